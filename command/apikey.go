@@ -0,0 +1,348 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-tty"
+	"github.com/ysugimoto/go-args"
+
+	"github.com/ysugimoto/ginger/config"
+	"github.com/ysugimoto/ginger/entity"
+	"github.com/ysugimoto/ginger/input"
+	"github.com/ysugimoto/ginger/logger"
+	"github.com/ysugimoto/ginger/request"
+)
+
+const (
+	APIKEYCREATE = "create"
+	APIKEYDELETE = "delete"
+	APIKEYLIST   = "list"
+	APIKEYATTACH = "attach"
+	APIKEYDETACH = "detach"
+	APIKEYHELP   = "help"
+)
+
+// ApiKey is the struct of AWS API Gateway API key management command.
+// This struct will be dispatched on "ginger apikey" subcommand.
+type ApiKey struct {
+	Command
+	log *logger.Logger
+}
+
+func NewApiKey() *ApiKey {
+	return &ApiKey{
+		log: logger.WithNamespace("ginger.apikey"),
+	}
+}
+
+// Show apikey command help.
+func (a *ApiKey) Help() string {
+	return commandHeader() + `
+apikey - (AWS API Gateway) API key management command.
+
+Usage:
+  $ ginger apikey|ak [operation] [options]
+
+Operation:
+  create : Create new API key
+  delete : Delete API key
+  list   : List API keys
+  attach : Attach API key to usage plan
+  detach : Detach API key from usage plan
+  help   : Show this help
+
+Options:
+  -n, --name        : [all] API key name
+      --plan        : [create,attach,detach] Usage plan name
+      --show-values : [list] Display key values
+`
+}
+
+// Run the command.
+func (a *ApiKey) Run(ctx *args.Context) error {
+	c := config.Load()
+	if !c.Exists() {
+		a.log.Error("Configuration file could not load. Run `ginger init` before.")
+		return errors.New("")
+	}
+	var err error
+	defer func() {
+		if err != nil {
+			a.log.Error(err.Error())
+			debugTrace(err)
+		}
+		c.Write()
+	}()
+
+	switch ctx.At(1) {
+	case APIKEYCREATE:
+		err = a.createApiKey(c, ctx)
+	case APIKEYDELETE:
+		err = a.deleteApiKey(c, ctx)
+	case APIKEYLIST:
+		err = a.listApiKey(c, ctx)
+	case APIKEYATTACH:
+		err = a.attachApiKey(c, ctx)
+	case APIKEYDETACH:
+		err = a.detachApiKey(c, ctx)
+	default:
+		fmt.Println(a.Help())
+	}
+	return err
+}
+
+// createApiKey creates new API key in local.
+//
+// >>> doc
+//
+// ## Create new API key
+//
+// Create new API Gateway API key.
+//
+// ```
+// $ ginger apikey create [options]
+// ```
+//
+// | option  | description                                                     |
+// |:-------:|:----------------------------------------------------------------|
+// | --name  | API key name. If this option isn't supplied, ginger will ask it |
+// | --plan  | Usage plan name to attach created key                           |
+//
+// Created API key is deployed on `ginger deploy apikey` or `ginger deploy all`.
+// The key value is generated by AWS, run `ginger apikey list --show-values` to see it after deployment.
+//
+// <<< doc
+func (a *ApiKey) createApiKey(c *config.Config, ctx *args.Context) error {
+	name := ctx.String("name")
+	if name == "" {
+		name = input.String("Type API key name")
+	}
+	if name == "" {
+		return exception("API key name is empty. Abort.")
+	} else if _, err := c.LoadApiKey(name); err == nil {
+		return exception("API key \"%s\" already defined.", name)
+	}
+
+	var plan *entity.UsagePlan
+	if p := ctx.String("plan"); p != "" {
+		var err error
+		if plan, err = c.LoadUsagePlan(p); err != nil {
+			return exception("Usage plan \"%s\" not defined.", p)
+		}
+	}
+
+	c.ApiKeys = append(c.ApiKeys, &entity.ApiKey{
+		Name:    name,
+		Enabled: true,
+	})
+	if plan != nil {
+		plan.ApiKeys = append(plan.ApiKeys, name)
+	}
+	a.log.Infof("API key \"%s\" created successfully.\n", name)
+	return nil
+}
+
+// deleteApiKey deletes API key.
+// If API key has been deployed on AWS API Gateway, also delete it.
+//
+// >>> doc
+//
+// ## Delete API key
+//
+// Delete API Gateway API key.
+//
+// ```
+// $ ginger apikey delete [options]
+// ```
+//
+// | option  | description             |
+// |:-------:|:------------------------|
+// | --name  | [Required] API key name |
+//
+// API key is also detached from all usage plans.
+//
+// <<< doc
+func (a *ApiKey) deleteApiKey(c *config.Config, ctx *args.Context) error {
+	name := ctx.String("name")
+	if name == "" {
+		name = c.ChooseApiKey()
+	}
+	k, err := c.LoadApiKey(name)
+	if err != nil {
+		return exception("API key \"%s\" not defined.", name)
+	}
+
+	if k.Id != "" {
+		if !ctx.Has("force") && !input.Bool("Also deletes from AWS API Gateway. Are you sure?") {
+			a.log.Warn("Abort.")
+			return nil
+		}
+		api := request.NewAPIGateway(c)
+		if api.ApiKeyExists(k.Id) {
+			if err := api.DeleteApiKey(k.Id); err != nil {
+				a.log.Error("Failed to delete from AWS. Please delete manually.")
+			}
+		} else {
+			a.log.Print("Not found in AWS API Gateway. Skip it.")
+		}
+	}
+
+	c.DeleteApiKey(name)
+	a.log.Info("API key deleted successfully.")
+	return nil
+}
+
+// listApiKey shows registered API keys.
+//
+// >>> doc
+//
+// ## List API keys
+//
+// List registered API keys and attached usage plans.
+// Only API keys which are defined in the project are listed.
+//
+// ```
+// $ ginger apikey list [options]
+// ```
+//
+// | option        | description                                                  |
+// |:-------------:|:-------------------------------------------------------------|
+// | --show-values | Display key values of deployed API keys instead of mask text |
+//
+// <<< doc
+func (a *ApiKey) listApiKey(c *config.Config, ctx *args.Context) error {
+	// Key value is secret, so it's fetched only when explicitly requested
+	values := map[string]string{}
+	if ctx.Has("show-values") {
+		api := request.NewAPIGateway(c)
+		for _, k := range c.ApiKeys {
+			if k.Id == "" {
+				continue
+			}
+			if value, err := api.GetApiKeyValue(k.Id); err == nil {
+				values[k.Id] = value
+			}
+		}
+	}
+
+	t, err := tty.Open()
+	if err != nil {
+		return exception("Couldn't open tty")
+	}
+	defer t.Close()
+	w, _, err := t.Size()
+	if err != nil {
+		return exception("Couldn't get tty size")
+	}
+	line := strings.Repeat("=", w)
+	fmt.Println(line)
+	fmt.Printf("%-24s %-12s %-8s %-24s %s\n", "Name", "ApiKeyId", "Enabled", "UsagePlans", "Value")
+	fmt.Println(line)
+	for i, k := range c.ApiKeys {
+		plans := []string{}
+		for _, u := range c.UsagePlans {
+			if u.HasApiKey(k.Name) {
+				plans = append(plans, u.Name)
+			}
+		}
+		value, ok := values[k.Id]
+		if !ok && k.Id != "" {
+			value = "********"
+		}
+		fmt.Printf("%-24s %-12s %-8t %-24s %s\n", k.Name, k.Id, k.Enabled, strings.Join(plans, ","), value)
+		if i != len(c.ApiKeys)-1 {
+			fmt.Println(strings.Repeat("-", w))
+		}
+	}
+	return nil
+}
+
+// attachApiKey attaches API key to usage plan.
+//
+// >>> doc
+//
+// ## Attach API key
+//
+// Attach API key to usage plan.
+//
+// ```
+// $ ginger apikey attach [options]
+// ```
+//
+// | option  | description                                                        |
+// |:-------:|:-------------------------------------------------------------------|
+// | --name  | API key name. If this option isn't supplied, ginger will ask it    |
+// | --plan  | Usage plan name. If this option isn't supplied, ginger will ask it |
+//
+// Usage plan must be defined in `Ginger.toml` as `[[usage_plans]]` section.
+// The relation is deployed on `ginger deploy apikey` or `ginger deploy all`.
+//
+// <<< doc
+func (a *ApiKey) attachApiKey(c *config.Config, ctx *args.Context) error {
+	k, u, err := a.chooseRelation(c, ctx)
+	if err != nil {
+		return err
+	}
+	if u.HasApiKey(k.Name) {
+		a.log.Warnf("API key \"%s\" has already been attached to \"%s\".\n", k.Name, u.Name)
+		return nil
+	}
+	u.ApiKeys = append(u.ApiKeys, k.Name)
+	a.log.Infof("API key \"%s\" attached to \"%s\" successfully.\n", k.Name, u.Name)
+	return nil
+}
+
+// detachApiKey detaches API key from usage plan.
+//
+// >>> doc
+//
+// ## Detach API key
+//
+// Detach API key from usage plan.
+//
+// ```
+// $ ginger apikey detach [options]
+// ```
+//
+// | option  | description                                                        |
+// |:-------:|:-------------------------------------------------------------------|
+// | --name  | API key name. If this option isn't supplied, ginger will ask it    |
+// | --plan  | Usage plan name. If this option isn't supplied, ginger will ask it |
+//
+// <<< doc
+func (a *ApiKey) detachApiKey(c *config.Config, ctx *args.Context) error {
+	k, u, err := a.chooseRelation(c, ctx)
+	if err != nil {
+		return err
+	}
+	if !u.HasApiKey(k.Name) {
+		a.log.Warnf("API key \"%s\" isn't attached to \"%s\".\n", k.Name, u.Name)
+		return nil
+	}
+	u.DetachApiKey(k.Name)
+	a.log.Infof("API key \"%s\" detached from \"%s\" successfully.\n", k.Name, u.Name)
+	return nil
+}
+
+// chooseRelation finds API key and usage plan from options, or asks them.
+func (a *ApiKey) chooseRelation(c *config.Config, ctx *args.Context) (*entity.ApiKey, *entity.UsagePlan, error) {
+	name := ctx.String("name")
+	if name == "" {
+		name = c.ChooseApiKey()
+	}
+	k, err := c.LoadApiKey(name)
+	if err != nil {
+		return nil, nil, exception("API key \"%s\" not defined.", name)
+	}
+	plan := ctx.String("plan")
+	if plan == "" {
+		plan = c.ChooseUsagePlan()
+	}
+	u, err := c.LoadUsagePlan(plan)
+	if err != nil {
+		return nil, nil, exception("Usage plan \"%s\" not defined. Add [[usage_plans]] section to Ginger.toml.", plan)
+	}
+	return k, u, nil
+}
@@ -0,0 +1,265 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-tty"
+	"github.com/ysugimoto/go-args"
+
+	"github.com/ysugimoto/ginger/config"
+	"github.com/ysugimoto/ginger/entity"
+	"github.com/ysugimoto/ginger/input"
+	"github.com/ysugimoto/ginger/logger"
+	"github.com/ysugimoto/ginger/request"
+)
+
+const (
+	AUTHORIZERCREATE = "create"
+	AUTHORIZERDELETE = "delete"
+	AUTHORIZERLIST   = "list"
+	AUTHORIZERHELP   = "help"
+)
+
+// Authorizer is the struct of AWS API Gateway authorizer management command.
+// This struct will be dispatched on "ginger authorizer" subcommand.
+type Authorizer struct {
+	Command
+	log *logger.Logger
+}
+
+func NewAuthorizer() *Authorizer {
+	return &Authorizer{
+		log: logger.WithNamespace("ginger.authorizer"),
+	}
+}
+
+// Show authorizer command help.
+func (a *Authorizer) Help() string {
+	return commandHeader() + `
+authorizer - (AWS API Gateway) authorizer management command.
+
+Usage:
+  $ ginger authorizer|au [operation] [options]
+
+Operation:
+  create : Create new authorizer
+  delete : Delete authorizer
+  list   : List authorizers
+  help   : Show this help
+
+Options:
+  -n, --name     : [all] Authorizer name
+      --type     : [create] Authorizer type [token|request|cognito]
+      --function : [create] Function name for lambda authorizer
+`
+}
+
+// Run the command.
+func (a *Authorizer) Run(ctx *args.Context) error {
+	c := config.Load()
+	if !c.Exists() {
+		a.log.Error("Configuration file could not load. Run `ginger init` before.")
+		return errors.New("")
+	}
+	var err error
+	defer func() {
+		if err != nil {
+			a.log.Error(err.Error())
+			debugTrace(err)
+		}
+		c.Write()
+	}()
+
+	switch ctx.At(1) {
+	case AUTHORIZERCREATE:
+		err = a.createAuthorizer(c, ctx)
+	case AUTHORIZERDELETE:
+		err = a.deleteAuthorizer(c, ctx)
+	case AUTHORIZERLIST:
+		err = a.listAuthorizer(c, ctx)
+	default:
+		fmt.Println(a.Help())
+	}
+	return err
+}
+
+// createAuthorizer creates new authorizer in local.
+//
+// >>> doc
+//
+// ## Create new authorizer
+//
+// Create new API Gateway authorizer.
+//
+// ```
+// $ ginger authorizer create [options]
+// ```
+//
+// | option     | description                                                                    |
+// |:----------:|:-------------------------------------------------------------------------------|
+// | --name     | Authorizer name. If this option isn't supplied, ginger will ask it             |
+// | --type     | Authorizer type. enable values are `token`, `request` or `cognito`             |
+// | --function | Function name for `token` or `request` authorizer. ginger will ask it if empty |
+//
+// For `cognito` authorizer, ginger asks Cognito user pool ARNs.
+// Created authorizer is deployed on `ginger deploy resource`.
+// To use authorizer for resource, run `ginger function mount` with `--authorizer` option,
+// or set `authorizer` field of integration in `Ginger.toml`.
+//
+// <<< doc
+func (a *Authorizer) createAuthorizer(c *config.Config, ctx *args.Context) error {
+	name := ctx.String("name")
+	if name == "" {
+		name = input.String("Type authorizer name")
+	}
+	if name == "" {
+		return exception("Authorizer name is empty. Abort.")
+	} else if _, err := c.LoadAuthorizer(name); err == nil {
+		return exception("Authorizer \"%s\" already defined.", name)
+	}
+
+	var aType string
+	switch strings.ToLower(ctx.String("type")) {
+	case "token":
+		aType = entity.AuthorizerToken
+	case "request":
+		aType = entity.AuthorizerRequest
+	case "cognito":
+		aType = entity.AuthorizerCognito
+	default:
+		aType = input.Choice("Choose authorizer type", []string{
+			entity.AuthorizerToken,
+			entity.AuthorizerRequest,
+			entity.AuthorizerCognito,
+		})
+	}
+	if aType == "" {
+		return exception("Authorizer type is empty. Abort.")
+	}
+
+	au := entity.NewAuthorizer(name, aType)
+	if au.IsLambda() {
+		fn := ctx.String("function")
+		if fn == "" {
+			fn = c.ChooseFunction()
+		}
+		if _, err := c.LoadFunction(fn); err != nil {
+			return exception("Function %s couldn't find in your project.", fn)
+		}
+		au.Function = fn
+	} else {
+		arns := input.String("Input Cognito user pool ARNs (comma separated)")
+		for _, arn := range strings.Split(arns, ",") {
+			if arn = strings.TrimSpace(arn); arn != "" {
+				au.ProviderArns = append(au.ProviderArns, arn)
+			}
+		}
+		if len(au.ProviderArns) == 0 {
+			return exception("Cognito user pool ARN is empty. Abort.")
+		}
+	}
+
+	c.Authorizers = append(c.Authorizers, au)
+	a.log.Infof("Authorizer \"%s\" created successfully.\n", name)
+	return nil
+}
+
+// deleteAuthorizer deletes authorizer.
+// If authorizer has been deployed on AWS API Gateway, also delete it.
+//
+// >>> doc
+//
+// ## Delete authorizer
+//
+// Delete API Gateway authorizer.
+//
+// ```
+// $ ginger authorizer delete [options]
+// ```
+//
+// | option  | description                |
+// |:-------:|:---------------------------|
+// | --name  | [Required] authorizer name |
+//
+// Authorizer cannot be deleted while some integrations refer it.
+//
+// <<< doc
+func (a *Authorizer) deleteAuthorizer(c *config.Config, ctx *args.Context) error {
+	name := ctx.String("name")
+	if name == "" {
+		name = c.ChooseAuthorizer()
+	}
+	au, err := c.LoadAuthorizer(name)
+	if err != nil {
+		return exception("Authorizer \"%s\" not defined.", name)
+	}
+
+	for _, r := range c.Resources {
+		for method, ig := range r.GetIntegrations() {
+			if ig.Authorizer != nil && *ig.Authorizer == name {
+				return exception("Authorizer is used by %s %s. Unmount it before delete.", method, r.Path)
+			}
+		}
+	}
+
+	if au.Id != "" && c.RestApiId != "" {
+		if !ctx.Has("force") && !input.Bool("Also deletes from AWS API Gateway. Are you sure?") {
+			a.log.Warn("Abort.")
+			return nil
+		}
+		api := request.NewAPIGateway(c)
+		if api.AuthorizerExists(c.RestApiId, au.Id) {
+			if err := api.DeleteAuthorizer(c.RestApiId, au.Id); err != nil {
+				a.log.Error("Failed to delete from AWS. Please delete manually.")
+			}
+		} else {
+			a.log.Print("Not found in AWS API Gateway. Skip it.")
+		}
+	}
+
+	c.DeleteAuthorizer(name)
+	a.log.Info("Authorizer deleted successfully.")
+	return nil
+}
+
+// listAuthorizer shows registered authorizers.
+//
+// >>> doc
+//
+// ## List authorizers
+//
+// List registered authorizers.
+//
+// ```
+// $ ginger authorizer list
+// ```
+//
+// <<< doc
+func (a *Authorizer) listAuthorizer(c *config.Config, ctx *args.Context) error {
+	t, err := tty.Open()
+	if err != nil {
+		return exception("Couldn't open tty")
+	}
+	defer t.Close()
+	w, _, err := t.Size()
+	if err != nil {
+		return exception("Couldn't get tty size")
+	}
+	line := strings.Repeat("=", w)
+	fmt.Println(line)
+	fmt.Printf("%-24s %-16s %-36s %-4s\n", "Name", "AuthorizerId", "Type", "Deployed")
+	fmt.Println(line)
+	for i, au := range c.Authorizers {
+		d := "no"
+		if au.Id != "" {
+			d = "yes"
+		}
+		fmt.Printf("%-24s %-16s %-36s %-4s\n", au.Name, au.Id, au.String(), d)
+		if i != len(c.Authorizers)-1 {
+			fmt.Println(strings.Repeat("-", w))
+		}
+	}
+	return nil
+}
@@ -3,12 +3,20 @@ package command
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	"archive/zip"
+	"io/ioutil"
 	"os/exec"
 	"path/filepath"
+	"text/template"
 
+	"github.com/ysugimoto/ginger/config"
 	"github.com/ysugimoto/ginger/entity"
 	"github.com/ysugimoto/ginger/logger"
 )
@@ -18,26 +26,63 @@ const parallelBuildNum = 5
 // builder builds go application dynamically.
 // It's funny go application executes `go build` command :-)
 type builder struct {
-	src     string
-	dest    string
-	libPath string
+	config *config.Config
+	dest   string
+	stage  string
+	cache  *buildCache
+	log    *logger.Logger
+
+	varsOnce sync.Once
+	vars     buildVars
+
+	mu     sync.Mutex
+	cached map[string]bool
 }
 
-func newBuilder(src, dest, libPath string) *builder {
+// buildVars is the values which can be used in ldflags template.
+type buildVars struct {
+	Function string
+	Version  string
+	GitSHA   string
+	Stage    string
+}
+
+func newBuilder(c *config.Config, dest string) *builder {
 	return &builder{
-		src:     src,
-		dest:    dest,
-		libPath: libPath,
+		config: c,
+		dest:   dest,
+		log:    logger.WithNamespace("ginger.build"),
+		cached: map[string]bool{},
 	}
 }
 
-// build builds go application by each functions
-func (b *builder) build(targets []*entity.Function) error {
-	log := logger.WithNamespace("ginger.build")
+// withStage sets stage name which is used in ldflags template.
+func (b *builder) withStage(stage string) *builder {
+	b.stage = stage
+	return b
+}
+
+// withCache enables build cache.
+func (b *builder) withCache() *builder {
+	b.cache = newBuildCache(b.config)
+	return b
+}
+
+// isCached returns true if binary of the function was restored from build cache.
+func (b *builder) isCached(name string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.cached[name]
+}
+
+// build builds go application by each functions, and returns build errors by function name.
+// Returned map doesn't have entry for succeeded functions.
+func (b *builder) build(targets []*entity.Function) map[string]error {
+	log := b.log
 
 	// Parallel build by each functions
 	index := 0
-	errorBuilds := []error{}
+	errorBuilds := map[string]error{}
 	for {
 		var end int
 		if len(targets) < index+parallelBuildNum {
@@ -46,13 +91,13 @@ func (b *builder) build(targets []*entity.Function) error {
 			end = index + 5
 		}
 		var wg sync.WaitGroup
-		// var mu sync.Mutex
 		for _, fn := range targets[index:end] {
+			fn := fn
 			wg.Add(1)
 			log.Printf("Building function: %s...\n", fn.Name)
 			success := make(chan struct{})
 			err := make(chan error)
-			go b.compile(fn.Name, success, err)
+			go b.compile(fn, success, err)
 			go func() {
 				defer func() {
 					wg.Done()
@@ -61,8 +106,10 @@ func (b *builder) build(targets []*entity.Function) error {
 				}()
 				select {
 				case e := <-err:
-					log.Errorf("Failed to build function: %s\n", e.Error())
-					errorBuilds = append(errorBuilds, e)
+					log.Errorf("Failed to build function %s: %s\n", fn.Name, e.Error())
+					b.mu.Lock()
+					errorBuilds[fn.Name] = e
+					b.mu.Unlock()
 					return
 				case <-success:
 					log.Infof("Function %s built successfully\n", fn.Name)
@@ -76,38 +123,247 @@ func (b *builder) build(targets []*entity.Function) error {
 		}
 		index += parallelBuildNum
 	}
+	return errorBuilds
+}
 
-	if len(errorBuilds) > 0 {
-		return errors.New("Either function build failed")
+// compile compiles go application and notify result to channels.
+func (b *builder) compile(fn *entity.Function, successChan chan struct{}, errChan chan error) {
+	if err := b.compileFunction(fn); err != nil {
+		errChan <- err
+	} else {
+		successChan <- struct{}{}
 	}
-	return nil
 }
 
-// compile compiles go application by `go build` command.
-// Note that runtime in AWS Lambda is linux, so we have to build as linux amd64 target.
-func (b *builder) compile(name string, successChan chan struct{}, errChan chan error) {
+// compileFunction compiles go application by `go build` command, and put binary as function name in dest directory.
+// Note that runtime in AWS Lambda is linux, so we have to build as linux target with function's architecture.
+// If build cache is enabled and function isn't changed since last build, cached binary is used.
+func (b *builder) compileFunction(fn *entity.Function) error {
 	buffer := new(bytes.Buffer)
-	out := filepath.Join(b.dest, name)
-	src := filepath.Join(b.src, name)
+	out := filepath.Join(b.dest, fn.Name)
+	src := filepath.Join(b.config.FunctionPath, fn.Name)
 
-	gopath := os.Getenv("GOPATH")
-	if gopath == "" {
-		gopath = b.libPath
-	} else {
-		gopath += ":" + b.libPath
+	args, err := b.buildArgs(fn)
+	if err != nil {
+		return err
 	}
-	cmd := exec.Command("go", "build", "-o", out)
+
+	var key string
+	if b.cache != nil {
+		if key, err = b.cache.key(b.config, fn, args, b.buildEnv(fn)); err != nil {
+			return err
+		} else if b.cache.restore(fn.Name, key, out) {
+			b.log.Printf("Function %s is not changed, use cached binary\n", fn.Name)
+			b.mu.Lock()
+			b.cached[fn.Name] = true
+			b.mu.Unlock()
+			return nil
+		}
+	}
+
+	cmd := exec.Command("go", append([]string{"build", "-o", out}, args...)...)
 	cmd.Dir = src
-	cmd.Env = buildEnv(map[string]string{
-		"GOOS":   "linux",
-		"GOARCH": "amd64",
-		"GOPATH": gopath,
-	})
+	cmd.Env = buildEnv(b.buildEnv(fn))
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = buffer
 	if err := cmd.Run(); err != nil {
-		errChan <- errors.New(string(buffer.Bytes()))
-	} else {
-		successChan <- struct{}{}
+		return errors.New(string(buffer.Bytes()))
+	}
+	if b.cache != nil {
+		if err := b.cache.store(fn.Name, key, out); err != nil {
+			b.log.Warnf("Failed to store build cache for %s: %s\n", fn.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// reproducibleBuildFlags returns `go build` flags to make binary identical for identical sources.
+// Since Go 1.18, VCS revision, time and modified state are stamped in binary by default, so they are disabled
+// as well as local paths.
+func reproducibleBuildFlags() []string {
+	return []string{"-trimpath", "-buildvcs=false"}
+}
+
+// buildArgs makes `go build` arguments from function's [build] section.
+// Binary is built with reproducible flags and empty build id in order to make reproducible artifact,
+// and for custom runtime, we build with lambda.norpc tag because RPC is used only on go1.x runtime.
+// The last argument is main package path.
+func (b *builder) buildArgs(fn *entity.Function) ([]string, error) {
+	tags := []string{}
+	if fn.IsCustomRuntime() {
+		tags = append(tags, "lambda.norpc")
+	}
+	tags = append(tags, fn.BuildTags()...)
+
+	ldflags := "-buildid="
+	if fn.Build != nil && fn.Build.Ldflags != "" {
+		rendered, err := b.renderLdflags(fn)
+		if err != nil {
+			return nil, err
+		}
+		ldflags += " " + rendered
+	}
+
+	args := append(reproducibleBuildFlags(), "-ldflags", ldflags)
+	if len(tags) > 0 {
+		args = append(args, "-tags", strings.Join(tags, ","))
+	}
+	return append(args, fn.MainPackage()), nil
+}
+
+// buildEnv makes environment variables to build function for AWS Lambda.
+func (b *builder) buildEnv(fn *entity.Function) map[string]string {
+	env := goEnv(b.config, fn.Name, "linux", fn.GoArch())
+	if fn.Build != nil && fn.Build.CgoEnabled != nil {
+		if *fn.Build.CgoEnabled {
+			env["CGO_ENABLED"] = "1"
+		} else {
+			env["CGO_ENABLED"] = "0"
+		}
+	}
+	return env
+}
+
+// renderLdflags renders ldflags template with build variables.
+// Template can use {{.Function}}, {{.Version}}, {{.GitSHA}} and {{.Stage}}.
+func (b *builder) renderLdflags(fn *entity.Function) (string, error) {
+	b.varsOnce.Do(func() {
+		b.vars = buildVars{
+			Version: gitOutput(b.config.Root, "describe", "--tags", "--always", "--dirty"),
+			GitSHA:  gitOutput(b.config.Root, "rev-parse", "HEAD"),
+			Stage:   b.stage,
+		}
+		if b.vars.Version == "" {
+			b.vars.Version = "dev"
+		}
+	})
+	vars := b.vars
+	vars.Function = fn.Name
+
+	tmpl, err := template.New(fn.Name).Option("missingkey=error").Parse(fn.Build.Ldflags)
+	if err != nil {
+		return "", exception("Invalid ldflags template for %s: %s", fn.Name, err.Error())
+	}
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, vars); err != nil {
+		return "", exception("Failed to render ldflags for %s: %s", fn.Name, err.Error())
+	}
+	return buf.String(), nil
+}
+
+// gitOutput runs git command and returns trimmed output, or empty string if failed.
+func gitOutput(dir string, arguments ...string) string {
+	cmd := exec.Command("git", arguments...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// Fixed modification time of zip entries.
+// Zip format cannot express time before 1980, so use the minimum value.
+var archiveModTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// archive archives built application binary and extra files to zip.
+// The binary is put as function name for go1.x runtime, or "bootstrap" for custom runtime.
+// Extra files which are matched with [build] files patterns are put as relative path from function directory.
+// Entries have fixed modification time and permission, so same inputs always produce byte-identical zip.
+func archive(c *config.Config, fn *entity.Function, binPath string) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	z := zip.NewWriter(buf)
+	bin, err := ioutil.ReadFile(binPath)
+	if err != nil {
+		return nil, exception("Binary file read error: %s", err.Error())
+	}
+	if err := writeZipEntry(z, fn.Handler(), bin, 0755); err != nil {
+		return nil, err
+	}
+
+	files, err := collectBuildFiles(filepath.Join(c.FunctionPath, fn.Name), fn)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == fn.Handler() {
+			return nil, exception("Extra file %s conflicts with function binary", name)
+		}
+		info, err := os.Stat(files[name])
+		if err != nil {
+			return nil, exception("Extra file read error: %s", err.Error())
+		}
+		body, err := ioutil.ReadFile(files[name])
+		if err != nil {
+			return nil, exception("Extra file read error: %s", err.Error())
+		}
+		// Keep executable bit only, because Lambda runs functions as other user
+		var perm os.FileMode = 0644
+		if info.Mode()&0100 != 0 {
+			perm = 0755
+		}
+		if err := writeZipEntry(z, name, body, perm); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := z.Close(); err != nil {
+		return nil, exception("Failed to close zip stream: %s", err.Error())
+	}
+	return buf.Bytes(), nil
+}
+
+// writeZipEntry writes file to zip with fixed modification time.
+func writeZipEntry(z *zip.Writer, name string, body []byte, perm os.FileMode) error {
+	header := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: archiveModTime,
+	}
+	header.SetMode(perm)
+	if f, err := z.CreateHeader(header); err != nil {
+		return exception("Failed to create zip header: %s", err.Error())
+	} else if _, err := f.Write(body); err != nil {
+		return exception("Failed to write %s to zip stream: %s", name, err.Error())
+	}
+	return nil
+}
+
+// collectBuildFiles finds extra files by [build] files patterns.
+// Returns map of relative path in zip to file path. Directory is included recursively.
+func collectBuildFiles(src string, fn *entity.Function) (map[string]string, error) {
+	files := map[string]string{}
+	if fn.Build == nil {
+		return files, nil
+	}
+	for _, pattern := range fn.Build.Files {
+		matches, err := filepath.Glob(filepath.Join(src, pattern))
+		if err != nil {
+			return nil, exception("Invalid file pattern %s: %s", pattern, err.Error())
+		} else if len(matches) == 0 {
+			return nil, exception("No files matched with pattern %s", pattern)
+		}
+		for _, match := range matches {
+			err := filepath.Walk(match, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				rel, err := filepath.Rel(src, path)
+				if err != nil || strings.HasPrefix(rel, "..") {
+					return fmt.Errorf("%s is outside of function directory", path)
+				}
+				files[filepath.ToSlash(rel)] = path
+				return nil
+			})
+			if err != nil {
+				return nil, exception("Failed to collect files: %s", err.Error())
+			}
+		}
 	}
+	return files, nil
 }
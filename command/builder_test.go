@@ -0,0 +1,259 @@
+package command
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/ysugimoto/ginger/config"
+	"github.com/ysugimoto/ginger/entity"
+)
+
+func TestArchiveIsReproducible(t *testing.T) {
+	root, err := ioutil.TempDir("", "ginger-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	c := &config.Config{Root: root, FunctionPath: filepath.Join(root, "functions")}
+	fn := &entity.Function{
+		Name:    "example",
+		Runtime: entity.RuntimeProvidedAl2023,
+		Build:   &entity.FunctionBuild{Files: []string{"templates"}},
+	}
+	bin := filepath.Join(root, "bootstrap")
+	writeTestFile(t, bin, "binary")
+	writeTestFile(t, filepath.Join(c.FunctionPath, fn.Name, "templates", "b.tmpl"), "b")
+	writeTestFile(t, filepath.Join(c.FunctionPath, fn.Name, "templates", "a.tmpl"), "a")
+
+	first, err := archive(c, fn, bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Modification time must not affect the package
+	later := time.Now().Add(time.Hour)
+	for _, path := range []string{bin, filepath.Join(c.FunctionPath, fn.Name, "templates", "a.tmpl")} {
+		if err := os.Chtimes(path, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	second, err := archive(c, fn, bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Errorf("archives of the same input are different")
+	}
+}
+
+func TestCollectBuildFiles(t *testing.T) {
+	root, err := ioutil.TempDir("", "ginger-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	src := filepath.Join(root, "example")
+	writeTestFile(t, filepath.Join(root, "outside.txt"), "outside")
+	writeTestFile(t, filepath.Join(src, "templates", "index.html"), "index")
+	writeTestFile(t, filepath.Join(src, "templates", "partials", "header.html"), "header")
+	writeTestFile(t, filepath.Join(src, "config.json"), "{}")
+
+	tests := []struct {
+		name    string
+		files   []string
+		expect  []string
+		isError bool
+	}{
+		{
+			name:   "no build section",
+			expect: []string{},
+		},
+		{
+			name:   "directory is included recursively",
+			files:  []string{"templates"},
+			expect: []string{"templates/index.html", "templates/partials/header.html"},
+		},
+		{
+			name:   "glob pattern",
+			files:  []string{"*.json"},
+			expect: []string{"config.json"},
+		},
+		{
+			name:    "pattern which doesn't match",
+			files:   []string{"*.yaml"},
+			isError: true,
+		},
+		{
+			name:    "file outside of function directory",
+			files:   []string{"../outside.txt"},
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := &entity.Function{Name: "example"}
+			if tt.files != nil {
+				fn.Build = &entity.FunctionBuild{Files: tt.files}
+			}
+			files, err := collectBuildFiles(src, fn)
+			if tt.isError {
+				if err == nil {
+					t.Errorf("expected error, got nil")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if len(files) != len(tt.expect) {
+				t.Fatalf("expected %v, got %v", tt.expect, files)
+			}
+			for _, name := range tt.expect {
+				if _, ok := files[name]; !ok {
+					t.Errorf("expected %s is collected, got %v", name, files)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildIsReproducibleAcrossCommits(t *testing.T) {
+	for _, command := range []string{"go", "git"} {
+		if _, err := exec.LookPath(command); err != nil {
+			t.Skipf("%s command is not found", command)
+		}
+	}
+	root, err := ioutil.TempDir("", "ginger-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	c := &config.Config{
+		Root:         root,
+		FunctionPath: filepath.Join(root, "functions"),
+		LibPath:      filepath.Join(root, ".ginger"),
+	}
+	fn := &entity.Function{Name: "example", Runtime: entity.RuntimeProvidedAl2023}
+	writeTestFile(t, filepath.Join(root, "go.mod"), "module example.com/project\n\ngo 1.18\n")
+	writeTestFile(t, filepath.Join(c.FunctionPath, fn.Name, "main.go"), "package main\n\nfunc main() {}\n")
+
+	git := func(arguments ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, arguments...)...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s", arguments, out)
+		}
+	}
+	build := func() []byte {
+		t.Helper()
+		dest, err := ioutil.TempDir("", "ginger-test-build")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dest)
+		if err := newBuilder(c, dest).compileFunction(fn); err != nil {
+			t.Fatal(err)
+		}
+		zip, err := archive(c, fn, filepath.Join(dest, fn.Name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return zip
+	}
+
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "first")
+	first := build()
+
+	// Another commit and untracked file change VCS stamp if it's enabled
+	git("commit", "-q", "--allow-empty", "-m", "second")
+	writeTestFile(t, filepath.Join(root, "untracked.txt"), "untracked")
+	second := build()
+
+	if !bytes.Equal(first, second) {
+		t.Errorf("packages of the same source at different commits are different")
+	}
+}
+
+func TestBuildMarksCachedFunction(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command is not found")
+	}
+	root, err := ioutil.TempDir("", "ginger-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	c := &config.Config{
+		Root:         root,
+		FunctionPath: filepath.Join(root, "functions"),
+		LibPath:      filepath.Join(root, ".ginger"),
+		CachePath:    filepath.Join(root, ".ginger", "cache"),
+	}
+	fn := &entity.Function{Name: "example", Runtime: entity.RuntimeProvidedAl2023}
+	writeTestFile(t, filepath.Join(root, "go.mod"), "module example.com/project\n\ngo 1.18\n")
+	writeTestFile(t, filepath.Join(c.FunctionPath, fn.Name, "main.go"), "package main\n\nfunc main() {}\n")
+
+	for _, expect := range []bool{false, true} {
+		dest, err := ioutil.TempDir("", "ginger-test-build")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dest)
+		b := newBuilder(c, dest).withCache()
+		if err := b.compileFunction(fn); err != nil {
+			t.Fatal(err)
+		}
+		if actual := b.isCached(fn.Name); actual != expect {
+			t.Errorf("expected cached %t, got %t", expect, actual)
+		}
+	}
+}
+
+func TestBuildReturnsErrorsByFunction(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command is not found")
+	}
+	root, err := ioutil.TempDir("", "ginger-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	c := &config.Config{
+		Root:         root,
+		FunctionPath: filepath.Join(root, "functions"),
+		LibPath:      filepath.Join(root, ".ginger"),
+	}
+	ok := &entity.Function{Name: "ok", Runtime: entity.RuntimeProvidedAl2023}
+	broken := &entity.Function{Name: "broken", Runtime: entity.RuntimeProvidedAl2023}
+	writeTestFile(t, filepath.Join(root, "go.mod"), "module example.com/project\n\ngo 1.18\n")
+	writeTestFile(t, filepath.Join(c.FunctionPath, ok.Name, "main.go"), "package main\n\nfunc main() {}\n")
+	writeTestFile(t, filepath.Join(c.FunctionPath, broken.Name, "main.go"), "package main\n\nfunc main() { undefined() }\n")
+
+	dest, err := ioutil.TempDir("", "ginger-test-build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dest)
+	errs := newBuilder(c, dest).build([]*entity.Function{ok, broken})
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 build error, got %d", len(errs))
+	}
+	if err, found := errs[broken.Name]; !found {
+		t.Errorf("expected build error of %s", broken.Name)
+	} else if !strings.Contains(err.Error(), "undefined") {
+		t.Errorf("expected compiler output in build error, got %s", err.Error())
+	}
+}
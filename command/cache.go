@@ -0,0 +1,220 @@
+package command
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/ysugimoto/ginger/config"
+	"github.com/ysugimoto/ginger/entity"
+)
+
+// buildCache is the struct which stores built function binaries by content hash.
+// Cache key is calculated from function sources, dependency sources, go.mod/go.sum, build configuration,
+// build flags, build environments and go version, so binary is reused when all of them are not changed.
+// Only the latest binary is kept for each function.
+type buildCache struct {
+	dir        string
+	goVersion  string
+	goModCache string
+}
+
+func newBuildCache(c *config.Config) *buildCache {
+	bc := &buildCache{
+		dir: c.CachePath,
+	}
+	if out, err := exec.Command("go", "version").Output(); err == nil {
+		bc.goVersion = strings.TrimSpace(string(out))
+	}
+	if out, err := exec.Command("go", "env", "GOMODCACHE").Output(); err == nil {
+		bc.goModCache = strings.TrimSpace(string(out))
+	}
+	return bc
+}
+
+// key calculates cache key of the function.
+// Dependency packages are listed by `go list -deps`, and packages in module cache are identified by go.sum.
+func (bc *buildCache) key(c *config.Config, fn *entity.Function, buildArgs []string, env map[string]string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "go:%s\nfunction:%s\nargs:%s\n", bc.goVersion, fn.Name, strings.Join(buildArgs, " "))
+	keys := []string{}
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "env:%s=%s\n", k, env[k])
+	}
+	// Function.toml isn't hashed as file because it's rewritten on each deployment,
+	// so only the fields which affect build are hashed
+	build, err := json.Marshal(struct {
+		Runtime      string
+		Architecture string
+		Build        *entity.FunctionBuild
+	}{fn.GetRuntime(), fn.GetArchitecture(), fn.Build})
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(h, "build:%s\n", build)
+
+	src := filepath.Join(c.FunctionPath, fn.Name)
+	if err := hashTree(h, src, fn); err != nil {
+		return "", err
+	}
+	if root := c.ModuleRoot(fn.Name); root != "" {
+		for _, name := range []string{"go.mod", "go.sum"} {
+			if err := hashFile(h, filepath.Join(root, name)); err != nil && !os.IsNotExist(err) {
+				return "", err
+			}
+		}
+	}
+
+	dirs, err := bc.dependencyDirs(src, buildArgs, env)
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range dirs {
+		if dir == src || strings.HasPrefix(dir, src+string(filepath.Separator)) {
+			continue
+		} else if bc.goModCache != "" && strings.HasPrefix(dir, bc.goModCache) {
+			continue
+		}
+		if err := hashPackage(h, dir); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// dependencyDirs lists directories of non-standard packages which the function depends on.
+func (bc *buildCache) dependencyDirs(src string, buildArgs []string, env map[string]string) ([]string, error) {
+	buffer := new(bytes.Buffer)
+	args := append([]string{"list", "-deps", "-f", "{{if not .Standard}}{{.Dir}}{{end}}"}, buildArgs...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = src
+	// buildEnv consumes overrides, so pass a copy
+	overrides := map[string]string{}
+	for k, v := range env {
+		overrides[k] = v
+	}
+	cmd.Env = buildEnv(overrides)
+	cmd.Stderr = buffer
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.New(string(buffer.Bytes()))
+	}
+	dirs := []string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			dirs = append(dirs, line)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// path returns cached binary path for the key.
+func (bc *buildCache) path(name, key string) string {
+	return filepath.Join(bc.dir, name, key)
+}
+
+// restore copies cached binary to dest if exists.
+func (bc *buildCache) restore(name, key, dest string) bool {
+	if _, err := os.Stat(bc.path(name, key)); err != nil {
+		return false
+	}
+	return copyFile(bc.path(name, key), dest, 0755) == nil
+}
+
+// store saves built binary as the key, and removes older binaries of the function.
+func (bc *buildCache) store(name, key, bin string) error {
+	dir := filepath.Join(bc.dir, name)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return copyFile(bin, bc.path(name, key), 0755)
+}
+
+// hashTree writes all files under function directory to hash.
+// Binaries which are built by `ginger fn build` and Function.toml are ignored.
+func hashTree(h hash.Hash, root string, fn *entity.Function) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if info.IsDir() {
+			if path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		} else if filepath.Dir(path) == root {
+			switch info.Name() {
+			case fn.Name, fn.Handler(), "Function.toml":
+				return nil
+			}
+		}
+		return hashFile(h, path)
+	})
+}
+
+// hashPackage writes files in package directory to hash.
+// Subdirectories are other packages, so they are not included.
+func hashPackage(h hash.Hash, dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		if err := hashFile(h, filepath.Join(dir, f.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hashFile writes file path and content to hash.
+func hashFile(h hash.Hash, path string) error {
+	fp, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	fmt.Fprintf(h, "file:%s\n", filepath.ToSlash(path))
+	_, err = io.Copy(h, fp)
+	return err
+}
+
+// copyFile copies file with permission.
+func copyFile(src, dest string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
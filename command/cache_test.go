@@ -0,0 +1,234 @@
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/ysugimoto/ginger/config"
+	"github.com/ysugimoto/ginger/entity"
+)
+
+func writeTestFile(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHashTree(t *testing.T) {
+	fn := &entity.Function{Name: "example", Runtime: entity.RuntimeProvidedAl2023}
+	tests := []struct {
+		name    string
+		modify  func(t *testing.T, src string)
+		changed bool
+	}{
+		{
+			name: "source is changed",
+			modify: func(t *testing.T, src string) {
+				writeTestFile(t, filepath.Join(src, "main.go"), "package main\n\nfunc main() { println() }\n")
+			},
+			changed: true,
+		},
+		{
+			name: "file is added in subdirectory",
+			modify: func(t *testing.T, src string) {
+				writeTestFile(t, filepath.Join(src, "templates", "index.html"), "<html></html>")
+			},
+			changed: true,
+		},
+		{
+			name: "file is renamed",
+			modify: func(t *testing.T, src string) {
+				if err := os.Rename(filepath.Join(src, "util.go"), filepath.Join(src, "helper.go")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			changed: true,
+		},
+		{
+			name: "built binary is ignored",
+			modify: func(t *testing.T, src string) {
+				writeTestFile(t, filepath.Join(src, fn.Name), "binary")
+			},
+		},
+		{
+			name: "bootstrap binary is ignored",
+			modify: func(t *testing.T, src string) {
+				writeTestFile(t, filepath.Join(src, fn.Handler()), "binary")
+			},
+		},
+		{
+			name: "Function.toml is ignored",
+			modify: func(t *testing.T, src string) {
+				writeTestFile(t, filepath.Join(src, "Function.toml"), "version = \"3\"\n")
+			},
+		},
+		{
+			name: "hidden directory is ignored",
+			modify: func(t *testing.T, src string) {
+				writeTestFile(t, filepath.Join(src, ".git", "HEAD"), "ref: refs/heads/master")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := ioutil.TempDir("", "ginger-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(src)
+			writeTestFile(t, filepath.Join(src, "main.go"), "package main\n\nfunc main() {}\n")
+			writeTestFile(t, filepath.Join(src, "util.go"), "package main\n")
+
+			sum := func() string {
+				h := sha256.New()
+				if err := hashTree(h, src, fn); err != nil {
+					t.Fatal(err)
+				}
+				return hex.EncodeToString(h.Sum(nil))
+			}
+			before := sum()
+			tt.modify(t, src)
+			if changed := before != sum(); changed != tt.changed {
+				t.Errorf("expected hash changed %t, got %t", tt.changed, changed)
+			}
+		})
+	}
+}
+
+func TestBuildCacheKey(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command is not found")
+	}
+	base := entity.Function{Name: "example", Runtime: entity.RuntimeProvidedAl2023}
+	args := []string{"-trimpath", "."}
+	env := map[string]string{"GOOS": "linux", "GOARCH": "arm64"}
+
+	tests := []struct {
+		name    string
+		modify  func(t *testing.T, c *config.Config, fn *entity.Function) ([]string, map[string]string)
+		changed bool
+	}{
+		{
+			name: "nothing is changed",
+			modify: func(t *testing.T, c *config.Config, fn *entity.Function) ([]string, map[string]string) {
+				return args, env
+			},
+		},
+		{
+			name: "function source is changed",
+			modify: func(t *testing.T, c *config.Config, fn *entity.Function) ([]string, map[string]string) {
+				writeTestFile(t, filepath.Join(c.FunctionPath, fn.Name, "main.go"), "package main\n\nimport \"example.com/project/lib\"\n\nfunc main() { lib.Do(); lib.Do() }\n")
+				return args, env
+			},
+			changed: true,
+		},
+		{
+			name: "dependency package is changed",
+			modify: func(t *testing.T, c *config.Config, fn *entity.Function) ([]string, map[string]string) {
+				writeTestFile(t, filepath.Join(c.Root, "lib", "lib.go"), "package lib\n\nfunc Do() { println() }\n")
+				return args, env
+			},
+			changed: true,
+		},
+		{
+			name: "go.mod is changed",
+			modify: func(t *testing.T, c *config.Config, fn *entity.Function) ([]string, map[string]string) {
+				writeTestFile(t, filepath.Join(c.Root, "go.mod"), "module example.com/project\n\ngo 1.19\n")
+				return args, env
+			},
+			changed: true,
+		},
+		{
+			name: "build flags are changed",
+			modify: func(t *testing.T, c *config.Config, fn *entity.Function) ([]string, map[string]string) {
+				return []string{"-trimpath", "-tags", "debug", "."}, env
+			},
+			changed: true,
+		},
+		{
+			name: "build environment is changed",
+			modify: func(t *testing.T, c *config.Config, fn *entity.Function) ([]string, map[string]string) {
+				return args, map[string]string{"GOOS": "linux", "GOARCH": "amd64"}
+			},
+			changed: true,
+		},
+		{
+			name: "build configuration is changed",
+			modify: func(t *testing.T, c *config.Config, fn *entity.Function) ([]string, map[string]string) {
+				fn.Build = &entity.FunctionBuild{Tags: []string{"debug"}}
+				return args, env
+			},
+			changed: true,
+		},
+		{
+			name: "architecture is changed",
+			modify: func(t *testing.T, c *config.Config, fn *entity.Function) ([]string, map[string]string) {
+				fn.Architecture = entity.ArchitectureArm64
+				return args, env
+			},
+			changed: true,
+		},
+		{
+			name: "deployment fields in Function.toml are changed",
+			modify: func(t *testing.T, c *config.Config, fn *entity.Function) ([]string, map[string]string) {
+				fn.Version = "3"
+				fn.MemorySize = 512
+				writeTestFile(t, filepath.Join(c.FunctionPath, fn.Name, "Function.toml"), "version = \"3\"\nmemory_size = 512\n")
+				return args, env
+			},
+		},
+		{
+			name: "unrelated package is changed",
+			modify: func(t *testing.T, c *config.Config, fn *entity.Function) ([]string, map[string]string) {
+				writeTestFile(t, filepath.Join(c.Root, "other", "other.go"), "package other\n\nfunc Do() {}\n")
+				return args, env
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := ioutil.TempDir("", "ginger-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(root)
+
+			fn := base
+			c := &config.Config{
+				Root:         root,
+				FunctionPath: filepath.Join(root, "functions"),
+				LibPath:      filepath.Join(root, ".ginger"),
+			}
+			writeTestFile(t, filepath.Join(root, "go.mod"), "module example.com/project\n\ngo 1.18\n")
+			writeTestFile(t, filepath.Join(root, "lib", "lib.go"), "package lib\n\nfunc Do() {}\n")
+			writeTestFile(t, filepath.Join(root, "other", "other.go"), "package other\n")
+			writeTestFile(t, filepath.Join(c.FunctionPath, fn.Name, "main.go"), "package main\n\nimport \"example.com/project/lib\"\n\nfunc main() { lib.Do() }\n")
+
+			bc := &buildCache{dir: filepath.Join(root, ".ginger", "cache"), goVersion: "go"}
+			key := func(args []string, env map[string]string) string {
+				k, err := bc.key(c, &fn, args, env)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return k
+			}
+			before := key(args, env)
+			modifiedArgs, modifiedEnv := tt.modify(t, c, &fn)
+			if changed := before != key(modifiedArgs, modifiedEnv); changed != tt.changed {
+				t.Errorf("expected key changed %t, got %t", tt.changed, changed)
+			}
+		})
+	}
+}
@@ -16,26 +16,30 @@ func commandHeader() string {
 }
 
 const (
-	VERSION   = "version"
-	INIT      = "init"
-	INSTALL   = "install"
-	CONFIG    = "config"
-	DEPLOY    = "deploy"
-	D         = "d"
-	STAGE     = "stage"
-	ST        = "st" // alias for "stage"
-	FUNCTION  = "function"
-	FN        = "fn" // alias for "function"
-	RESOURCE  = "resource"
-	R         = "r" // alias for "resource"
-	STORAGE   = "storage"
-	S         = "s" // alias for "storage"
-	SCHEDULER = "scheduler"
-	SC        = "sc" // alias for schedule
+	VERSION    = "version"
+	INIT       = "init"
+	INSTALL    = "install"
+	CONFIG     = "config"
+	DEPLOY     = "deploy"
+	D          = "d"
+	STAGE      = "stage"
+	ST         = "st" // alias for "stage"
+	FUNCTION   = "function"
+	FN         = "fn" // alias for "function"
+	RESOURCE   = "resource"
+	R          = "r" // alias for "resource"
+	STORAGE    = "storage"
+	S          = "s" // alias for "storage"
+	SCHEDULER  = "scheduler"
+	SC         = "sc" // alias for schedule
+	AUTHORIZER = "authorizer"
+	AU         = "au" // alias for authorizer
+	APIKEY     = "apikey"
+	AK         = "ak" // alias for apikey
+	SERVE      = "serve"
+	LAYER      = "layer"
 )
 
-const LAMBDARPCPORT = "6666"
-
 // Command is the interface implemented by structs that can run the command
 // and show help as usage.
 type Command interface {
@@ -29,11 +29,13 @@ Usage:
   $ ginger config [options]
 
 Options:
-  --profile : Using profile name
-  --region  : Set project region
-  --role    : Set default lambda execution role
-  --bucket  : Set S3 bucket name which you want to use
-  --hook    : Register deploy hook command
+  --profile               : Using profile name
+  --region                : Set project region
+  --role                  : Set default lambda execution role
+  --bucket                : Set S3 bucket name which you want to use
+  --hook                  : Register deploy hook command
+  --code-upload           : Set function package upload mode, "auto" or "s3"
+  --code-upload-threshold : Set package size in MB to upload via S3 on "auto" mode
 `
 }
 
@@ -49,12 +51,20 @@ Options:
 // $ ginger config [options]
 // ```
 //
-// | option    | description                                                                  |
-// |:---------:|:-----------------------------------------------------------------------------|
-// | --profile | Accout profile name. If empty, ginger uses `default` or environment variable |
-// | --region  | Region name to deploy                                                        |
-// | --bucket  | S3 bucket name                                                               |
-// | --hook    | Deploy hook command                                                          |
+// | option                  | description                                                                  |
+// |:-----------------------:|:-----------------------------------------------------------------------------|
+// | --profile               | Accout profile name. If empty, ginger uses `default` or environment variable |
+// | --region                | Region name to deploy                                                        |
+// | --bucket                | S3 bucket name                                                               |
+// | --hook                  | Deploy hook command                                                          |
+// | --code-upload           | Function package upload mode. `auto` (default) or `s3`                       |
+// | --code-upload-threshold | Package size in MB to upload via S3 on `auto` mode. default is 50            |
+//
+// Function package is sent inline on deployment, but AWS Lambda rejects zip which is larger than 50MB.
+// So the package which exceeds threshold, or all packages on `s3` mode, are uploaded to S3 bucket
+// under `.ginger/functions/[function name]/[timestamp]-[hash].zip` key and deployed from there.
+// If bucket versioning is enabled, the object version is also passed to AWS Lambda.
+// Note that the bucket must be in the same region as functions.
 //
 // <<< doc
 func (c *Config) Run(ctx *args.Context) error {
@@ -86,6 +96,18 @@ func (c *Config) Run(ctx *args.Context) error {
 		conf.DeployHookCommand = v
 		c.log.Print("Set deploy hook command.")
 	}
+	if v = ctx.String("code-upload"); v != "" {
+		if v != config.CodeUploadAuto && v != config.CodeUploadS3 {
+			c.log.Errorf("Invalid code upload mode \"%s\". Mode must be \"%s\" or \"%s\"\n", v, config.CodeUploadAuto, config.CodeUploadS3)
+			return errors.New("")
+		}
+		conf.CodeUpload = v
+		c.log.Printf("Set function package upload mode as \"%s\"\n", v)
+	}
+	if n := ctx.Int("code-upload-threshold"); n > 0 {
+		conf.CodeUploadThreshold = int64(n)
+		c.log.Printf("Set package upload threshold as %dMB\n", n)
+	}
 	c.log.Info("Configuration updated!")
 	return nil
 }
@@ -1,17 +1,22 @@
 package command
 
 import (
-	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
-	"archive/zip"
 	"io/ioutil"
 	"os/exec"
 	"path/filepath"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
 	"github.com/ysugimoto/go-args"
 
 	"github.com/ysugimoto/ginger/config"
@@ -28,7 +33,9 @@ const (
 	DEPLOYSTORAGE  = "storage"
 	DEPLOYSCHEDULE = "schedule"
 	DEPLOYS        = "s"
+	DEPLOYAPIKEY   = "apikey"
 	DEPLOYALL      = "all"
+	DEPLOYPLAN     = "plan"
 	DEPLOYHELP     = "help"
 )
 
@@ -36,12 +43,14 @@ const (
 // deploy syncs between local and AWS.
 type Deploy struct {
 	Command
-	log *logger.Logger
+	log    *logger.Logger
+	result *deployResult
 }
 
 func NewDeploy() *Deploy {
 	return &Deploy{
-		log: logger.WithNamespace("ginger.deploy"),
+		log:    logger.WithNamespace("ginger.deploy"),
+		result: newDeployResult(),
 	}
 }
 
@@ -58,12 +67,16 @@ Subcommand:
   resource : Deploy resources (default: all, one of path if --name option supplied)
   storage  : Deploy storage
   schedule : Deploy schedulers
+  apikey   : Deploy API keys and usage plans
   all      : Deploy both of functions and apis
+  plan     : Show differences between local and AWS without deployment
   help     : Show this help
 
 Options:
-  --name  : Target fucntion name
-  --stage : Target api stage
+  --name     : Target fucntion name
+  --stage    : Target api stage
+  --no-cache : Build functions without build cache
+  --strategy : Traffic shifting strategy of function deployment [all-at-once|canary|linear]
 `
 }
 
@@ -90,6 +103,16 @@ func (d *Deploy) Run(ctx *args.Context) error {
 		d.log.Error("Configuration file could not load. Run `ginger init` before.")
 		return errors.New("")
 	}
+	// Plan never mutates AWS and local configuration, so run it before deployment phase
+	if ctx.At(1) == DEPLOYPLAN {
+		err := d.planDeployment(c, ctx)
+		if err != nil && err != planChangesDetected {
+			d.log.Error(err.Error())
+			debugTrace(err)
+		}
+		return err
+	}
+
 	var err error
 	defer func() {
 		if err != nil {
@@ -123,35 +146,68 @@ func (d *Deploy) Run(ctx *args.Context) error {
 			return err
 		}
 		err = d.deployStorage(c, ctx)
-	case DEPLOYALL:
+	case DEPLOYAPIKEY:
 		if err = d.runHook(c); err != nil {
 			return err
 		}
-		d.log.Print("========== Function Deployment ==========")
-		if err = d.deployFunction(c, ctx); err != nil {
-			return err
-		}
-		d.log.Print("========== Storage Deployment ==========")
-		if err = d.deployStorage(c, ctx); err != nil {
+		err = d.deployUsagePlans(c, ctx)
+	case DEPLOYALL:
+		if err = d.runHook(c); err != nil {
 			return err
 		}
-		d.log.Print("========== Scheduler Deployment ==========")
-		if err = d.deploySchedulers(c, ctx); err != nil {
+		err = d.deployAll(c, ctx)
+	default:
+		fmt.Println(d.Help())
+	}
+	err = d.report(err)
+	return err
+}
+
+// deployAll deploys functions, storage, schedulers, resources and stage in order.
+func (d *Deploy) deployAll(c *config.Config, ctx *args.Context) error {
+	d.log.Print("========== Function Deployment ==========")
+	if err := d.deployFunction(c, ctx); err != nil {
+		return err
+	}
+	d.log.Print("========== Storage Deployment ==========")
+	if err := d.deployStorage(c, ctx); err != nil {
+		return err
+	}
+	d.log.Print("========== Scheduler Deployment ==========")
+	if err := d.deploySchedulers(c, ctx); err != nil {
+		return err
+	}
+	d.log.Print("========== Resource Deployment ==========")
+	if err := d.deployResource(c, ctx); err != nil {
+		return err
+	}
+
+	if s := ctx.String("stage"); s != "" {
+		d.log.Print("========== Stage Deployment ==========")
+		if err := d.deployStage(c, ctx); err != nil {
 			return err
 		}
-		d.log.Print("========== Resource Deployment ==========")
-		if err = d.deployResource(c, ctx); err != nil {
+	}
+
+	if len(c.ApiKeys) > 0 || len(c.UsagePlans) > 0 {
+		d.log.Print("========== Usage Plan Deployment ==========")
+		if err := d.deployUsagePlans(c, ctx); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		if s := ctx.String("stage"); s != "" {
-			d.log.Print("========== Stage Deployment ==========")
-			if err = d.deployStage(c, ctx); err != nil {
-				return err
-			}
-		}
-	default:
-		fmt.Println(d.Help())
+// report prints deployment summary and returns error if some deployments failed.
+// Deployment functions record failures of each resource and continue others,
+// so caller should pass the result of deployment through this function.
+func (d *Deploy) report(err error) error {
+	d.result.print()
+	if err != nil {
+		return err
+	}
+	if n := d.result.failures(); n > 0 {
+		return exception("%d deployment(s) failed.", n)
 	}
 	return nil
 }
@@ -190,9 +246,108 @@ func (d *Deploy) runHook(c *config.Config) error {
 // $ ginger deploy function [options]
 // ```
 //
-// | option | description                                                       |
-// |:------:|:------------------------------------------------------------------|
-// | --name | Function name. if this option didn't supply, deploy all functions |
+// | option     | description                                                              |
+// |:----------:|:-------------------------------------------------------------------------|
+// | --name     | Function name. if this option didn't supply, deploy all functions        |
+// | --no-cache | Build all functions without build cache                                  |
+// | --strategy | Traffic shifting strategy. `all-at-once` (default), `canary` or `linear` |
+//
+// Build can be configured by `[build]` section in `Function.toml`:
+//
+// ```
+// [build]
+// tags = ["production"]
+// ldflags = "-s -w -X main.version={{.Version}} -X main.commit={{.GitSHA}} -X main.stage={{.Stage}}"
+// cgo_enabled = false
+// main = "./cmd/handler"
+// files = ["templates/*.html", "certs"]
+// ```
+//
+// | field       | description                                                                                          |
+// |:-----------:|:-----------------------------------------------------------------------------------------------------|
+// | tags        | Build tags                                                                                           |
+// | ldflags     | `-ldflags` value. `{{.Function}}`, `{{.Version}}` (git describe), `{{.GitSHA}}` and `{{.Stage}}` (`--stage` option) are available |
+// | cgo_enabled | `CGO_ENABLED` value. if not specified, go command's default is used                                 |
+// | main        | Main package path relative from function directory. default is function directory                   |
+// | files       | Files or glob patterns relative from function directory to include in zip. directory is included recursively |
+//
+// Built binaries are cached in `.ginger/cache` by hash of function sources, dependencies, `go.mod`/`go.sum`, `[build]` section, runtime, build flags and target architecture,
+// so unchanged functions are not rebuilt. And, if archived zip is identical to deployed code (compare with `CodeSha256`), uploading code is skipped.
+// Furthermore, if configuration in `Function.toml` is also unchanged since the deployed version, deployment of the function is skipped.
+//
+// Each deployment publishes new version, and it is recorded as `version` in `Function.toml`.
+// Aliases can be declared in `Function.toml` as well:
+//
+// ```
+// [[aliases]]
+// name = "live"
+// version = "3"
+//
+// [[aliases]]
+// name = "staging"
+// ```
+//
+// Alias which has `version` points the version, and alias which doesn't have `version` follows the version published on each deployment.
+// API Gateway integrations and scheduler targets can reference alias as `[function name]:[alias]`.
+//
+// Concurrency is reconciled on each deployment. `reserved_concurrency` caps concurrent executions of the function,
+// and `provisioned_concurrency` of alias keeps execution environments initialized for the alias:
+//
+// ```
+// reserved_concurrency = 100
+//
+// [[aliases]]
+// name = "live"
+// provisioned_concurrency = 10
+// ```
+//
+// If `reserved_concurrency` is removed, the function becomes unreserved, and if `provisioned_concurrency` is removed or zero, provisioned concurrency is deleted.
+// Provisioned concurrency of aliases which aren't declared is also deleted, and the total of `provisioned_concurrency` must not exceed `reserved_concurrency`.
+//
+// Failed asynchronous invocations, e.g. triggered by schedules or S3, can be handled by `[async]` section:
+//
+// ```
+// [async]
+// dead_letter_queue = "arn:aws:sqs:ap-northeast-1:123456789012:example-dlq"
+// maximum_retry_attempts = 2
+// maximum_event_age = 3600
+// on_success = "arn:aws:sns:ap-northeast-1:123456789012:example-success"
+// on_failure = "arn:aws:sqs:ap-northeast-1:123456789012:example-failure"
+// ```
+//
+// `dead_letter_queue` accepts SQS queue or SNS topic ARN, and execution role needs permission to send messages to it.
+// `maximum_retry_attempts` (0 to 2), `maximum_event_age` (60 to 21600 seconds) and destinations are applied to the function and all aliases.
+//
+// Lambda layers can be attached by `layers` field, which accepts layer name in `layers` directory or layer version ARN:
+//
+// ```
+// layers = ["tools", "arn:aws:lambda:ap-northeast-1:123456789012:layer:shared:3"]
+// ```
+//
+// Layer name is resolved to the latest published version on each deployment, so publish layer by `ginger layer publish` before.
+// Up to 5 layers can be attached, and removing `layers` detaches all layers.
+//
+// Traffic of alias can be shifted to new version gradually by `[deployment]` section:
+//
+// ```
+// [deployment]
+// strategy = "canary"
+// alias = "live"
+// percentage = 10
+// interval = 5
+// error_threshold = 0
+// throttle_threshold = 0
+// alarms = ["example-latency"]
+// ```
+//
+// `canary` routes `percentage` of traffic to new version for `interval` minutes, then shifts all traffic.
+// `linear` increases traffic by `percentage` in each `interval` minutes until all traffic.
+// While baking, ginger watches `Errors` and `Throttles` metrics of new version and `alarms` every minute,
+// and rolls alias back to previous version automatically if metrics exceed thresholds or either of alarms is in `ALARM` state.
+// Because CloudWatch metrics arrive late, each step is watched 3 more minutes after `interval` before traffic is shifted further.
+// `--strategy` option takes precedence over `strategy` field, and default is `all-at-once` which shifts all traffic immediately.
+//
+// Packages larger than 50MB are uploaded via S3 bucket. See `ginger config` to configure upload mode.
 //
 // <<< doc
 func (d *Deploy) deployFunction(c *config.Config, ctx *args.Context) error {
@@ -237,6 +392,25 @@ func (d *Deploy) deployFunction(c *config.Config, ctx *args.Context) error {
 	}
 	defer os.RemoveAll(buildDir)
 
+	// Validate lambda runtimes, deployment strategies, concurrency, async settings and layers
+	for _, f := range targets {
+		if err := f.ValidateRuntime(); err != nil {
+			return exception("Invalid runtime for function \"%s\": %s", f.Name, err.Error())
+		}
+		if err := f.ValidateDeployment(f.DeploymentStrategy(ctx.String("strategy"))); err != nil {
+			return exception("Invalid deployment for function \"%s\": %s", f.Name, err.Error())
+		}
+		if err := f.ValidateConcurrency(); err != nil {
+			return exception("Invalid concurrency for function \"%s\": %s", f.Name, err.Error())
+		}
+		if err := f.ValidateAsync(); err != nil {
+			return exception("Invalid async configuration for function \"%s\": %s", f.Name, err.Error())
+		}
+		if err := f.ValidateLayers(); err != nil {
+			return exception("Invalid layers for function \"%s\": %s", f.Name, err.Error())
+		}
+	}
+
 	// Validate lambda exection roles
 	for _, f := range targets {
 		if f.Role != "" {
@@ -249,34 +423,238 @@ func (d *Deploy) deployFunction(c *config.Config, ctx *args.Context) error {
 		}
 	}
 
-	// Build functions
-	builder := newBuilder(c.FunctionPath, buildDir, c.LibPath)
-	if err := builder.build(targets); err != nil {
-		return exception("Failed to build lambda function. %s", err.Error())
+	// Build functions.
+	// Even if some functions failed to build, continue to deploy succeeded functions.
+	builder := newBuilder(c, buildDir).withStage(ctx.String("stage"))
+	if !ctx.Has("no-cache") {
+		builder = builder.withCache()
 	}
+	buildErrors := builder.build(targets)
 
 	// Deploy to AWS
 	lambda := request.NewLambda(c)
 	for _, fn := range targets {
+		if err, ok := buildErrors[fn.Name]; ok {
+			d.result.fail("function", fn.Name, err)
+			continue
+		}
 		// Check binary existence
 		binPath := filepath.Join(buildDir, fn.Name)
 		if _, err := os.Stat(binPath); err != nil {
 			d.log.Errorf("Build function binary not found for %s. skip it\n", fn.Name)
+			d.result.fail("function", fn.Name, err)
 			continue
 		}
 		if fn.Role == "" {
 			fn.Role = c.DefaultLambdaRole
 		}
 		d.log.Printf("Archiving zip for %s...\n", fn.Name)
-		buffer, err := d.archive(fn, binPath)
+		buffer, err := archive(c, fn, binPath)
 		if err != nil {
 			d.log.Errorf("Archive error for %s: %s\n", fn.Name, err.Error())
+			d.result.fail("function", fn.Name, err)
+			continue
+		}
+		if builder.isCached(fn.Name) && d.isDeployed(c, lambda, fn, buffer) {
+			d.log.Printf("Function %s is not changed since version %s, skip to deploy\n", fn.Name, fn.Version)
+			d.result.skip("function", fn.Name, "Not changed")
 			continue
 		}
 		d.log.Printf("Deploying function %s to AWS Lambda...\n", fn.Name)
-		if arn, err := lambda.DeployFunction(fn, buffer); err == nil {
-			d.log.Infof("Function %s deployed successfully!\n", fn.Name)
-			fn.Arn = arn
+		result, code, err := lambda.DeployFunction(fn, buffer)
+		if err != nil {
+			d.result.fail("function", fn.Name, err)
+			continue
+		}
+		d.log.Infof("Function %s deployed successfully as version %s!\n", fn.Name, *result.Version)
+		fn.Arn = *result.FunctionArn
+		fn.Version = *result.Version
+		if err := recordDeployment(c, fn, result, code, ""); err != nil {
+			d.log.Warnf("Failed to record deployment history of %s: %s\n", fn.Name, err.Error())
+		}
+		if err := d.deployAliases(c, lambda, fn, ctx.String("strategy")); err != nil {
+			d.result.fail("function", fn.Name, err)
+			continue
+		}
+		if err := d.deployConcurrency(lambda, fn); err != nil {
+			d.result.fail("function", fn.Name, err)
+			continue
+		}
+		if err := d.deployAsyncConfig(lambda, fn); err != nil {
+			d.result.fail("function", fn.Name, err)
+			continue
+		}
+		d.result.success("function", fn.Name)
+	}
+	return nil
+}
+
+// isDeployed returns true if the version which is recorded in Function.toml is deployed with the same code and configuration.
+// Code is compared with both deployment history and deployed function because it may be updated outside of ginger,
+// and configuration is compared with the snapshot in deployment history.
+// Note that layers which are referred without version never match because snapshot pins them to the deployed versions.
+func (d *Deploy) isDeployed(c *config.Config, lambda *request.LambdaRequest, fn *entity.Function, zipBytes []byte) bool {
+	if fn.Version == "" {
+		return false
+	}
+	h, err := c.LoadHistory(fn.Name)
+	if err != nil {
+		return false
+	}
+	deployed := h.Find(fn.Version)
+	if deployed == nil || deployed.Config == nil {
+		return false
+	}
+	sum := sha256.Sum256(zipBytes)
+	codeSha256 := base64.StdEncoding.EncodeToString(sum[:])
+	if deployed.CodeSha256 != codeSha256 {
+		return false
+	}
+	current, err := lambda.GetFunction(fn.Name)
+	if err != nil || aws.StringValue(current.CodeSha256) != codeSha256 {
+		return false
+	}
+	snapshot := *deployed.Config
+	snapshot.Arn = fn.Arn
+	return reflect.DeepEqual(&snapshot, fn)
+}
+
+// recordDeployment appends published version to deployment history of the function.
+// Function configuration is recorded as snapshot in order to restore it on rollback.
+func recordDeployment(
+	c *config.Config,
+	fn *entity.Function,
+	result *lambda.FunctionConfiguration,
+	code *lambda.FunctionCode,
+	rollbackOf string,
+) error {
+	h, err := c.LoadHistory(fn.Name)
+	if err != nil {
+		return err
+	}
+	snapshot := *fn
+	// Pin layers to the versions which are attached on this deployment, so rollback restores them
+	if len(result.Layers) > 0 {
+		snapshot.Layers = []string{}
+		for _, layer := range result.Layers {
+			snapshot.Layers = append(snapshot.Layers, aws.StringValue(layer.Arn))
+		}
+	}
+	d := &entity.Deployment{
+		Version:    aws.StringValue(result.Version),
+		CodeSha256: aws.StringValue(result.CodeSha256),
+		GitCommit:  gitOutput(c.Root, "rev-parse", "HEAD"),
+		DeployedAt: time.Now(),
+		RollbackOf: rollbackOf,
+		Config:     &snapshot,
+	}
+	if code != nil && code.S3Key != nil {
+		d.S3Bucket = aws.StringValue(code.S3Bucket)
+		d.S3Key = aws.StringValue(code.S3Key)
+		d.S3ObjectVersion = aws.StringValue(code.S3ObjectVersion)
+	} else if prev := h.FindArtifact(d.CodeSha256); prev != nil {
+		// Code isn't uploaded because it's identical to deployed one, so reuse its artifact
+		d.S3Bucket = prev.S3Bucket
+		d.S3Key = prev.S3Key
+		d.S3ObjectVersion = prev.S3ObjectVersion
+	}
+	h.Add(d)
+	return c.WriteHistory(fn.Name, h)
+}
+
+// deployAliases points declared aliases to their versions.
+// Alias which doesn't have version follows the version which is published on this deployment.
+// If deployment strategy is canary or linear, traffic of [deployment] alias is shifted gradually.
+func (d *Deploy) deployAliases(c *config.Config, lambda *request.LambdaRequest, fn *entity.Function, strategy string) error {
+	strategy = fn.DeploymentStrategy(strategy)
+	for _, alias := range fn.Aliases {
+		if strategy != entity.StrategyAllAtOnce && alias.Name == fn.Deployment.Alias {
+			continue
+		}
+		version := alias.Version
+		if version == "" {
+			version = fn.Version
+		}
+		if err := lambda.PutAlias(fn.Name, alias.Name, version); err != nil {
+			return exception("Failed to put alias %s for %s: %s", alias.Name, fn.Name, err.Error())
+		}
+	}
+	if strategy == entity.StrategyAllAtOnce {
+		return nil
+	}
+
+	d.log.Printf("Deploying %s with %s strategy...\n", fn.Name, strategy)
+	shift := newTrafficShift(lambda, request.NewCloudWatch(c), d.log, fn, strategy)
+	if err := shift.run(fn.Version); err != nil {
+		return err
+	}
+	// Pinned alias is moved to new version because the version has been released safely
+	if alias := fn.GetAlias(fn.Deployment.Alias); alias.Version != "" {
+		alias.Version = fn.Version
+	}
+	return nil
+}
+
+// deployConcurrency reconciles reserved concurrency of function and provisioned concurrency of aliases.
+// Aliases must have been deployed before because provisioned concurrency is allocated for alias.
+func (d *Deploy) deployConcurrency(lambda *request.LambdaRequest, fn *entity.Function) error {
+	reserved, err := lambda.GetReservedConcurrency(fn.Name)
+	if err != nil {
+		return exception("Failed to get reserved concurrency for %s: %s", fn.Name, err.Error())
+	}
+	switch {
+	case fn.ReservedConcurrency == nil && reserved != nil:
+		err = lambda.DeleteReservedConcurrency(fn.Name)
+	case fn.ReservedConcurrency != nil && (reserved == nil || *reserved != *fn.ReservedConcurrency):
+		err = lambda.PutReservedConcurrency(fn.Name, *fn.ReservedConcurrency)
+	}
+	if err != nil {
+		return exception("Failed to update reserved concurrency for %s: %s", fn.Name, err.Error())
+	}
+
+	provisioned, err := lambda.ListProvisionedConcurrency(fn.Name)
+	if err != nil {
+		return exception("Failed to list provisioned concurrency for %s: %s", fn.Name, err.Error())
+	}
+	// Delete configurations first in order to release concurrency for declared aliases
+	for qualifier := range provisioned {
+		if _, err := strconv.ParseInt(qualifier, 10, 64); err == nil {
+			// Provisioned concurrency for version isn't managed by ginger
+			continue
+		} else if alias := fn.GetAlias(qualifier); alias != nil && alias.ProvisionedConcurrency > 0 {
+			continue
+		}
+		if err := lambda.DeleteProvisionedConcurrency(fn.Name, qualifier); err != nil {
+			return exception("Failed to delete provisioned concurrency for %s:%s: %s", fn.Name, qualifier, err.Error())
+		}
+	}
+	for _, alias := range fn.Aliases {
+		if alias.ProvisionedConcurrency == 0 || provisioned[alias.Name] == alias.ProvisionedConcurrency {
+			continue
+		}
+		if err := lambda.PutProvisionedConcurrency(fn.Name, alias.Name, alias.ProvisionedConcurrency); err != nil {
+			return exception("Failed to update provisioned concurrency for %s:%s: %s", fn.Name, alias.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// deployAsyncConfig reconciles asynchronous invocation configuration of function and aliases.
+// Dead letter queue is applied as function configuration, so it isn't handled here.
+func (d *Deploy) deployAsyncConfig(lambda *request.LambdaRequest, fn *entity.Function) error {
+	qualifiers := []string{""}
+	for _, alias := range fn.Aliases {
+		qualifiers = append(qualifiers, alias.Name)
+	}
+	for _, qualifier := range qualifiers {
+		var err error
+		if fn.Async != nil && fn.Async.HasInvokeConfig() {
+			err = lambda.PutEventInvokeConfig(fn.Name, qualifier, fn.Async)
+		} else {
+			err = lambda.DeleteEventInvokeConfig(fn.Name, qualifier)
+		}
+		if err != nil {
+			return exception("Failed to update async invoke configuration for %s: %s", fn.Name, err.Error())
 		}
 	}
 	return nil
@@ -294,55 +672,45 @@ func (d *Deploy) deploySchedulers(c *config.Config, ctx *args.Context) error {
 		return nil
 	}
 	for _, sc := range scs {
-		arn, err := cw.GetScheduleArn(sc.Name)
+		arn, err := cw.CreateOrUpdateSchedule(sc)
 		if err != nil {
-			return nil
-		}
-		arn, err = cw.CreateOrUpdateSchedule(sc)
-		if err != nil {
-			return nil
+			d.result.fail("scheduler", sc.Name, err)
+			continue
 		}
-		if sc.Functions == nil {
+		if len(sc.Functions) == 0 {
+			d.result.skip("scheduler", sc.Name, "No target functions")
 			continue
 		}
-		for _, name := range sc.Functions {
-			fn, err := lambda.GetFunction(name)
-			if err != nil {
-				return nil
-			}
-			if err := lambda.AddCloudWatchPermission(*fn.FunctionName, arn); err != nil {
-				return nil
-			}
-			if err = cw.PutTarget(sc.Name, fn.FunctionArn); err != nil {
-				return nil
-			}
+		if err := d.putScheduleTargets(cw, lambda, sc, arn); err != nil {
+			d.result.fail("scheduler", sc.Name, err)
+			continue
 		}
+		d.result.success("scheduler", sc.Name)
 	}
 	return nil
 }
 
-// archive archives built application binary to zip.
-func (d *Deploy) archive(fn *entity.Function, binPath string) ([]byte, error) {
-	buf := new(bytes.Buffer)
-	z := zip.NewWriter(buf)
-	bin, err := ioutil.ReadFile(binPath)
-	if err != nil {
-		return nil, exception("Binary file read error: %s", err.Error())
-	}
-	header := &zip.FileHeader{
-		Name:           fn.Name,
-		Method:         zip.Deflate,
-		ExternalAttrs:  0777 << 16,
-		CreatorVersion: 3 << 8,
-	}
-	if f, err := z.CreateHeader(header); err != nil {
-		return nil, exception("Failed to create zip header: %s", err.Error())
-	} else if _, err := f.Write(bin); err != nil {
-		return nil, exception("Failed to write binary to zip stream: %s", err.Error())
-	} else if err := z.Close(); err != nil {
-		return nil, exception("Failed to close zip stream: %s", err.Error())
-	}
-	return buf.Bytes(), nil
+// putScheduleTargets attaches scheduler to target functions.
+func (d *Deploy) putScheduleTargets(
+	cw *request.CloudWatchRequest,
+	lambda *request.LambdaRequest,
+	sc *entity.Scheduler,
+	arn string,
+) error {
+	for _, name := range sc.Functions {
+		// Function can be qualified by alias like "name:alias"
+		functionArn, err := lambda.ResolveFunctionArn(name)
+		if err != nil {
+			return exception("Function %s couldn't find in AWS: %s", name, err.Error())
+		}
+		if err := lambda.AddCloudWatchPermission(name, arn); err != nil {
+			return err
+		}
+		if err := cw.PutTarget(sc.Name, functionArn); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // deployAPI deploys resources to AWS APIGateway.
@@ -358,17 +726,47 @@ func (d *Deploy) archive(fn *entity.Function, binPath string) ([]byte, error) {
 // ```
 //
 // If resource has some integrations, create integration as well.
+// Authorizers which are defined in `Ginger.toml` are also created before integrations.
 //
 // | option  | description                                                         |
 // |:-------:|:--------------------------------------------------------------------|
 // | --stage | Stage name. If this option is supplied, create deployment to stage. |
 //
+// If CORS is configured, ginger also puts `OPTIONS` method with mock integration which responds preflight,
+// and adds `Access-Control-Allow-Origin` header to the responses of S3 integration.
+// CORS is configured as project default or per resource in `Ginger.toml`:
+//
+// ```
+// [cors]
+// allow_origins = ["https://example.com"]
+// allow_headers = ["Content-Type", "Authorization"]
+// max_age = 600
+// allow_credentials = false
+//
+// [[resources]]
+// path = "/internal"
+// [resources.cors]
+// disabled = true
+// ```
+//
+// If `allow_methods` is empty, the methods of mounted integrations are allowed, and `ANY` is expanded to concrete methods.
+// `allow_credentials = true` requires explicit `allow_origins` because browsers reject credentialed request for `*` origin.
+// Lambda proxy integration cannot map response headers, so the function must respond `Access-Control-Allow-Origin` header by itself.
+//
 // <<< doc
 func (d *Deploy) deployResource(c *config.Config, ctx *args.Context) (err error) {
 	if len(c.Resources) == 0 {
 		d.log.Warn("No resources found. Skip to deploy APIGateway.")
 		return nil
 	}
+	// Validate CORS settings before any resource is changed
+	for _, r := range c.Resources {
+		if cors := c.ResolveCors(r); cors != nil {
+			if err := cors.Validate(); err != nil {
+				return exception("Invalid CORS setting for resource %s: %s", r.Path, err.Error())
+			}
+		}
+	}
 	d.log.AddNamespace("resource")
 	defer d.log.RemoveNamespace("resource")
 	api := request.NewAPIGateway(c)
@@ -382,40 +780,61 @@ func (d *Deploy) deployResource(c *config.Config, ctx *args.Context) (err error)
 	}
 
 	// Probably root "/" resource created automatically, check existence in local
-	var rootId string
 	if r, err := c.LoadResource("/"); err != nil {
-		rootId, err = api.GetResourceIdByPath(c.RestApiId, "/")
+		rootId, err := api.GetResourceIdByPath(c.RestApiId, "/")
 		if err != nil {
-			return nil
+			return exception("Failed to get root resource: %s", err.Error())
 		}
 		rs := entity.NewResource(rootId, "/")
 		c.Resources = append(c.Resources, rs)
 	} else if r.Id == "" {
-		r.Id, err = api.GetResourceIdByPath(c.RestApiId, "/")
-		rootId = r.Id
-	} else {
-		rootId = r.Id
+		if r.Id, err = api.GetResourceIdByPath(c.RestApiId, "/"); err != nil {
+			return exception("Failed to get root resource: %s", err.Error())
+		}
 	}
 
-	for _, r := range c.Resources {
-		// If "Id" exists, the resource has already been deployed
-		if r.Id != "" && api.ResourceExists(c.RestApiId, r.Id) {
-			d.log.Infof("Resource %s has already been deployed.\n", r.Path)
-		} else {
-			api.CreateResourceRecursive(c.RestApiId, r.Path)
+	// Authorizers must be deployed before methods refer them
+	for _, au := range c.Authorizers {
+		if err := api.PutAuthorizer(c.RestApiId, au); err != nil {
+			d.result.fail("authorizer", au.Name, err)
+			continue
 		}
-		if igs := r.GetIntegrations(); igs != nil {
-			for method, integration := range igs {
-				if err = api.PutIntegration(c.RestApiId, r.Id, method, integration); err != nil {
-					return nil
-				}
-			}
+		d.result.success("authorizer", au.Name)
+	}
+
+	for _, r := range c.Resources {
+		if err := d.deployOneResource(api, c, r); err != nil {
+			d.result.fail("resource", r.Path, err)
+			continue
 		}
+		d.result.success("resource", r.Path)
 	}
-	// Obviously succeed, returns nil
 	return nil
 }
 
+// deployOneResource creates resource and puts its integrations.
+func (d *Deploy) deployOneResource(api *request.APIGatewayRequest, c *config.Config, r *entity.Resource) error {
+	// If "Id" exists, the resource has already been deployed
+	if r.Id != "" && api.ResourceExists(c.RestApiId, r.Id) {
+		d.log.Infof("Resource %s has already been deployed.\n", r.Path)
+	} else if err := api.CreateResourceRecursive(c.RestApiId, r.Path); err != nil {
+		return err
+	}
+	cors := c.ResolveCors(r)
+	for method, integration := range r.GetIntegrations() {
+		if err := api.PutIntegration(c.RestApiId, r.Id, method, integration, cors); err != nil {
+			return err
+		}
+	}
+	if cors == nil || len(r.GetIntegrations()) == 0 {
+		return nil
+	} else if r.GetIntegration("OPTIONS") != nil {
+		d.log.Warnf("OPTIONS method is mounted on %s. Skip to put CORS preflight.\n", r.Path)
+		return nil
+	}
+	return api.PutCors(c.RestApiId, r.Id, cors, r.GetIntegrations())
+}
+
 // deployStorage deploys storage items to AWS S3.
 //
 // >>> doc
@@ -453,7 +872,11 @@ func (d *Deploy) deployStorage(c *config.Config, ctx *args.Context) error {
 
 	for _, so := range locals {
 		d.log.Printf("Uploading local %s -> s3://%s/%s...\n", so.Key, bucket, so.Key)
-		s3.PutObject(bucket, so)
+		if err := s3.PutObject(bucket, so); err != nil {
+			d.result.fail("storage", so.Key, err)
+			continue
+		}
+		d.result.success("storage", so.Key)
 	}
 	return nil
 }
@@ -480,12 +903,65 @@ func (d *Deploy) listLocalObjects(root string) ([]*entity.StorageObject, error)
 	return objects, err
 }
 
+// deployStage creates deployment to the stage and syncs stage variables.
+//
+// >>> doc
+//
+// ## Deploy stage
+//
+// Create deployment of REST API to the stage, and sync stage variables which are defined in `stages/[stage].toml`.
+// The variables which are removed in local are also removed from the stage.
+//
+// ```
+// $ ginger deploy all --stage [stage]
+// $ ginger stage deploy --name [stage]
+// ```
+//
+// Stage settings also can be defined in `stages/[stage].toml`, and they are applied on deployment:
+//
+// ```
+// name = "production"
+// tracing = true
+//
+// [variables]
+// foo = "bar"
+//
+// [throttle]
+// burst_limit = 100
+// rate_limit = 50.0
+//
+// [throttle.methods."users/{id}/GET"]
+// burst_limit = 10
+// rate_limit = 5.0
+//
+// [cache]
+// enabled = true
+// cluster_size = "0.5"
+// ttl = 300
+//
+// [access_log]
+// destination_arn = "arn:aws:logs:us-east-1:123456789012:log-group:api-access-log"
+// format = "$context.requestId $context.status"
+// ```
+//
+// The settings which are not defined are left as they are on AWS, e.g. access log format is kept if `format` is empty.
+// If `[throttle]` is defined, throttling of method overrides which are removed from `[throttle.methods]` is reset to the stage's one.
+// The overrides which don't have other settings like logging or caching are removed from AWS.
+//
+// <<< doc
 func (d *Deploy) deployStage(c *config.Config, ctx *args.Context) error {
+	return d.deployStageByName(c, ctx.String("stage"), ctx.String("message"))
+}
+
+// deployStageByName creates deployment for the named stage.
+func (d *Deploy) deployStageByName(c *config.Config, name, message string) error {
 	d.log.AddNamespace("stage")
 	defer d.log.RemoveNamespace("stage")
 
-	name := ctx.String("stage")
-	_, err := c.LoadStage(name)
+	if c.RestApiId == "" {
+		return exception("REST API hasn't been created yet. Run `ginger deploy resource` before.")
+	}
+	stg, err := c.LoadStage(name)
 	if err != nil {
 		d.log.Warnf("Stage \"%s\" doesn't exists. Create...\n", name)
 		fileName := filepath.Join(c.StagePath, fmt.Sprintf("%s.toml", name))
@@ -493,11 +969,109 @@ func (d *Deploy) deployStage(c *config.Config, ctx *args.Context) error {
 		if err = ioutil.WriteFile(fileName, []byte(template), 0644); err != nil {
 			return exception("Create stage error: %s", err.Error())
 		}
+		stg = &entity.Stage{
+			Name:      name,
+			Variables: make(map[string]string),
+		}
 	}
 	api := request.NewAPIGateway(c)
-	if err = api.Deploy(c.RestApiId, name, ctx.String("message")); err != nil {
+	if err = api.Deploy(c.RestApiId, name, message); err != nil {
+		d.result.fail("stage", name, err)
 		return nil
 	}
+	if err = api.UpdateStageVariables(c.RestApiId, name, stg.Variables); err != nil {
+		d.result.fail("stage", name, err)
+		return nil
+	}
+	if err = api.UpdateStageSettings(c.RestApiId, stg); err != nil {
+		d.result.fail("stage", name, err)
+		return nil
+	}
+	d.result.success("stage", name)
+	return nil
+}
+
+// deployUsagePlans deploys API keys and usage plans to AWS API Gateway.
+//
+// >>> doc
+//
+// ## Deploy API keys and usage plans
+//
+// Deploy API keys and usage plans which are defined in `Ginger.toml`.
+//
+// ```
+// $ ginger deploy apikey
+// ```
+//
+// Usage plan is declared in `Ginger.toml` like:
+//
+// ```
+// [[usage_plans]]
+// name = "free"
+// stages = ["production"]
+// api_keys = ["partner"]
+//
+// [usage_plans.throttle]
+// burst_limit = 10
+// rate_limit = 5.0
+//
+// [usage_plans.quota]
+// limit = 1000
+// period = "DAY"
+// ```
+//
+// The stages which haven't been deployed yet are skipped, deploy stage before.
+// API keys which are detached from plan in local are also detached on AWS.
+// To require API key for method, set `api_key_required = true` to integration.
+//
+// <<< doc
+func (d *Deploy) deployUsagePlans(c *config.Config, ctx *args.Context) error {
+	d.log.AddNamespace("usageplan")
+	defer d.log.RemoveNamespace("usageplan")
+
+	if c.RestApiId == "" {
+		return exception("REST API hasn't been created yet. Run `ginger deploy resource` before.")
+	}
+	api := request.NewAPIGateway(c)
+	for _, k := range c.ApiKeys {
+		if err := api.PutApiKey(k); err != nil {
+			d.result.fail("apikey", k.Name, err)
+			continue
+		}
+		d.result.success("apikey", k.Name)
+	}
 
+	deployed := map[string]bool{}
+	for _, s := range api.GetStages(c.RestApiId) {
+		deployed[aws.StringValue(s.StageName)] = true
+	}
+	for _, u := range c.UsagePlans {
+		stages := []string{}
+		for _, s := range u.Stages {
+			if _, ok := deployed[s]; !ok {
+				d.log.Warnf("Stage \"%s\" hasn't been deployed yet. Skip to attach it.\n", s)
+				continue
+			}
+			stages = append(stages, s)
+		}
+		if err := api.PutUsagePlan(c.RestApiId, u, stages); err != nil {
+			d.result.fail("usageplan", u.Name, err)
+			continue
+		}
+		keyIds := []string{}
+		for _, name := range u.ApiKeys {
+			k, err := c.LoadApiKey(name)
+			if err != nil || k.Id == "" {
+				d.log.Warnf("API key \"%s\" hasn't been deployed. Skip to attach it.\n", name)
+				continue
+			}
+			keyIds = append(keyIds, k.Id)
+		}
+		if err := api.SyncUsagePlanKeys(u.Id, keyIds); err != nil {
+			d.result.fail("usageplan", u.Name, err)
+			continue
+		}
+		d.result.success("usageplan", u.Name)
+	}
 	return nil
 }
@@ -23,3 +23,18 @@ func debugTrace(err error) {
 func exception(message string, binds ...interface{}) error {
 	return errors.New(strings.TrimRight(fmt.Sprintf(message, binds...), "\n") + "\n")
 }
+
+// exitStatus is the error which exits ginger with the status without error message.
+type exitStatus int
+
+func (s exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(s))
+}
+
+// ExitStatus returns exit status of ginger for the error which command returns.
+func ExitStatus(err error) int {
+	if s, ok := err.(exitStatus); ok {
+		return int(s)
+	}
+	return 1
+}
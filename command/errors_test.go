@@ -0,0 +1,24 @@
+package command
+
+import (
+	"testing"
+)
+
+func TestExitStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		expect int
+	}{
+		{name: "command error", err: exception("Failed"), expect: 1},
+		{name: "plan changes detected", err: planChangesDetected, expect: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := ExitStatus(tt.err); actual != tt.expect {
+				t.Errorf("expected %d, got %d", tt.expect, actual)
+			}
+		})
+	}
+}
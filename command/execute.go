@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strings"
 	"time"
 
 	"net/rpc"
@@ -13,43 +14,78 @@ import (
 
 	"github.com/aws/aws-lambda-go/lambda/messages"
 	"github.com/ysugimoto/ginger/config"
+	"github.com/ysugimoto/ginger/entity"
 )
 
 // Execute `go xxx` command with our context
 func execGoCommand(ctx context.Context, c *config.Config, name, subcommand string, arguments []string) error {
-	gopath := os.Getenv("GOPATH")
-	if gopath == "" {
-		gopath = c.LibPath
-	} else {
-		gopath += ":" + c.LibPath
-	}
 	args := []string{subcommand}
 	if arguments != nil {
 		args = append(args, arguments...)
 	}
 	cmd := exec.CommandContext(ctx, "go", args...)
 	cmd.Dir = filepath.Join(c.FunctionPath, name)
-	cmd.Env = buildEnv(map[string]string{
-		"GOOS":                runtime.GOOS,
-		"GOARCH":              "amd64",
-		"GOPATH":              gopath,
-		"_LAMBDA_SERVER_PORT": LAMBDARPCPORT,
-	})
+	cmd.Env = buildEnv(goEnv(c, name, runtime.GOOS, "amd64"))
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
+// localBuildArguments makes `go build` arguments to run function on local.
+// Build tags and main package of [build] section are applied, but ldflags are not because they may depend on deployment.
+func localBuildArguments(fn *entity.Function, out string) []string {
+	args := []string{"-o", out}
+	if tags := fn.BuildTags(); len(tags) > 0 {
+		args = append(args, "-tags", strings.Join(tags, ","))
+	}
+	return append(args, fn.MainPackage())
+}
+
+// goEnv makes environment variables to run go command for the function.
+// If function is managed by Go modules, go command runs in module mode with -mod flag and GOPATH isn't touched.
+// Otherwise, go command runs in GOPATH mode with ginger's library directory.
+func goEnv(c *config.Config, name, goos, goarch string) map[string]string {
+	return goSourceEnv(c, filepath.Join(c.FunctionPath, name), goos, goarch)
+}
+
+// goSourceEnv makes environment variables to run go command in the source directory, e.g. layer directory.
+func goSourceEnv(c *config.Config, src, goos, goarch string) map[string]string {
+	env := map[string]string{
+		"GOOS":   goos,
+		"GOARCH": goarch,
+	}
+	if c.ModuleRootOf(src) != "" {
+		// Respect -mod flag if user specified explicitly
+		flags := os.Getenv("GOFLAGS")
+		if !strings.Contains(flags, "-mod=") {
+			flags = strings.TrimSpace(flags + " -mod=" + c.ModFlagOf(src))
+		}
+		env["GO111MODULE"] = "on"
+		env["GOFLAGS"] = flags
+		return env
+	}
+
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		gopath = c.LibPath
+	} else {
+		gopath += ":" + c.LibPath
+	}
+	env["GO111MODULE"] = "off"
+	env["GOPATH"] = gopath
+	return env
+}
+
 // Call Local Lambda RPC server like actual AWS's way
-func execLambdaRPC(timeout int64, source, clientContext []byte) (*messages.InvokeResponse, error) {
-	client, err := rpc.Dial("tcp", "127.0.0.1:"+LAMBDARPCPORT)
+func execLambdaRPC(port string, timeout int64, source, clientContext []byte) (*messages.InvokeResponse, error) {
+	client, err := rpc.Dial("tcp", "127.0.0.1:"+port)
 	if err != nil {
 		return nil, exception("Failed to connect local lambda RPC: %s", err.Error())
 	}
 	defer client.Close()
 	req := &messages.InvokeRequest{
 		Payload:   source,
-		RequestId: fmt.Sprintf("ginger-invoke-%d", time.Now().Unix()),
+		RequestId: fmt.Sprintf("ginger-invoke-%d", time.Now().UnixNano()),
 		Deadline: messages.InvokeRequest_Timestamp{
 			Seconds: time.Now().UTC().Unix() + timeout,
 			Nanos:   0,
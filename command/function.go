@@ -8,14 +8,19 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"syscall"
-	"time"
 
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"io/ioutil"
-	"os/exec"
 	"os/signal"
 	"path/filepath"
 
+	"github.com/aws/aws-lambda-go/lambda/messages"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/iancoleman/strcase"
 	"github.com/mattn/go-tty"
 	"github.com/ysugimoto/go-args"
@@ -31,18 +36,24 @@ import (
 
 const (
 	// Subcommands
-	FUNCTIONCREATE  = "create"
-	FUNCTIONDELETE  = "delete"
-	FUNCTIONINVOKE  = "invoke"
-	FUNCTIONDEPLOY  = "deploy"
-	FUNCTIONMOUNT   = "mount"
-	FUNCTIONUNMOUNT = "unmount"
-	FUNCTIONLIST    = "list"
-	FUNCTIONHELP    = "help"
-	FUNCTIONLOG     = "log"
-	FUNCTIONBUILD   = "build"
-	FUNCTIONTEST    = "test"
-	FUNCTIONRUN     = "run"
+	FUNCTIONCREATE   = "create"
+	FUNCTIONDELETE   = "delete"
+	FUNCTIONINVOKE   = "invoke"
+	FUNCTIONDEPLOY   = "deploy"
+	FUNCTIONMOUNT    = "mount"
+	FUNCTIONUNMOUNT  = "unmount"
+	FUNCTIONLIST     = "list"
+	FUNCTIONHELP     = "help"
+	FUNCTIONLOG      = "log"
+	FUNCTIONBUILD    = "build"
+	FUNCTIONTEST     = "test"
+	FUNCTIONRUN      = "run"
+	FUNCTIONWATCH    = "watch"
+	FUNCTIONMIGRATE  = "migrate-runtime"
+	FUNCTIONPROMOTE  = "promote"
+	FUNCTIONROLLBACK = "rollback"
+	FUNCTIONHISTORY  = "history"
+	FUNCTIONDLQ      = "dlq"
 
 	// Event names
 	eventNameNone       = "(None)"
@@ -81,25 +92,40 @@ Usage:
   $ ginger fn [operation] [options]
 
 Operation:
-  create  : Create new function
-  delete  : Delete function
-  invoke  : Invoke function
-  mount   : Mount function to destination path
-  unmount : Unmount function from destination path
-  deploy  : Deploy functions
-  list    : List functions
-  log     : Tail function log
-  build   : Build function
-  test    : Run unit test
-  run     : Run function on local
-  help    : Show this help
+  create          : Create new function
+  delete          : Delete function
+  invoke          : Invoke function
+  mount           : Mount function to destination path
+  unmount         : Unmount function from destination path
+  deploy          : Deploy functions
+  list            : List functions
+  log             : Tail function log
+  build           : Build function
+  test            : Run unit test
+  run             : Run function on local
+  watch           : Run function on local and reload on changes
+  migrate-runtime : Migrate function to custom runtime
+  promote         : Point function alias to published version
+  rollback        : Roll back function to previous deployment
+  history         : Show deployment history
+  dlq             : Peek or redrive dead letter queue messages
+  help            : Show this help
 
 Options:
-  -n, --name    : [all] Function name
-  -e, --event   : [create] Purpose of function event [s3|apigateway]
-  -e, --event   : [invoke] Event source (JSON string) or "@file" for filename
-  -p, --path    : [mount] Path name
-      --method  : [mount] Method name to integration
+  -n, --name       : [all] Function name
+  -e, --event      : [create] Purpose of function event [s3|apigateway]
+  -e, --event      : [invoke] Event source (JSON string) or "@file" for filename
+  -p, --path       : [mount] Path name
+      --method     : [mount] Method name to integration
+      --authorizer : [mount] Authorizer name to protect integration
+      --invoke     : [watch] Invoke function after reloaded
+      --artifact   : [build] Output path of deployable zip package
+      --runtime    : [create, migrate-runtime] Lambda runtime [provided.al2023|provided.al2|go1.x]
+      --arch       : [create, migrate-runtime] Lambda architecture [x86_64|arm64]
+      --alias      : [promote, rollback] Alias name
+      --version    : [promote] Published version. default is the latest deployed version
+      --to         : [rollback] Version to roll back. default is the previous deployment
+      --redrive    : [dlq] Invoke function with dead letter queue messages again
 `
 }
 
@@ -127,7 +153,8 @@ func (f *Function) Run(ctx *args.Context) error {
 	case FUNCTIONINVOKE:
 		err = f.invokeFunction(c, ctx)
 	case FUNCTIONDEPLOY:
-		err = NewDeploy().deployFunction(c, ctx)
+		d := NewDeploy()
+		err = d.report(d.deployFunction(c, ctx))
 	case FUNCTIONMOUNT:
 		err = f.mountFunction(c, ctx)
 	case FUNCTIONLIST:
@@ -140,6 +167,18 @@ func (f *Function) Run(ctx *args.Context) error {
 		err = f.testFunction(c, ctx)
 	case FUNCTIONRUN:
 		err = f.runFunction(c, ctx)
+	case FUNCTIONWATCH:
+		err = f.watchFunction(c, ctx)
+	case FUNCTIONMIGRATE:
+		err = f.migrateRuntime(c, ctx)
+	case FUNCTIONPROMOTE:
+		err = f.promoteFunction(c, ctx)
+	case FUNCTIONROLLBACK:
+		err = f.rollbackFunction(c, ctx)
+	case FUNCTIONHISTORY:
+		err = f.historyFunction(c, ctx)
+	case FUNCTIONDLQ:
+		err = f.dlqFunction(c, ctx)
 	default:
 		fmt.Println(f.Help())
 	}
@@ -158,10 +197,12 @@ func (f *Function) Run(ctx *args.Context) error {
 // $ ginger function create [options]
 // ```
 //
-// | option  | description                                                                                              |
-// |:-------:|:---------------------------------------------------------------------------------------------------------|
-// | --name  | Function name. If this option isn't supplied, ginger will ask it                                         |
-// | --event | Function event source. function template switches by this option. enable values are `s3` or `apigateway` |
+// | option    | description                                                                                              |
+// |:---------:|:---------------------------------------------------------------------------------------------------------|
+// | --name    | Function name. If this option isn't supplied, ginger will ask it                                         |
+// | --event   | Function event source. function template switches by this option. enable values are `s3` or `apigateway` |
+// | --runtime | Lambda runtime. enable values are `provided.al2023`, `provided.al2` or `go1.x`. default is `provided.al2023` |
+// | --arch    | Lambda architecture. enable values are `x86_64` or `arm64`. default is `x86_64`                          |
 //
 // <<< doc
 func (f *Function) createFunction(c *config.Config, ctx *args.Context) error {
@@ -194,16 +235,46 @@ func (f *Function) createFunction(c *config.Config, ctx *args.Context) error {
 		})
 	}
 
+	runtime := ctx.String("runtime")
+	if runtime == "" {
+		runtime = entity.RuntimeProvidedAl2023
+	}
+	arch := ctx.String("arch")
+	if arch == "" {
+		arch = entity.ArchitectureX86_64
+	}
+	if err := (&entity.Function{Runtime: runtime, Architecture: arch}).ValidateRuntime(); err != nil {
+		return exception("Invalid runtime: %s", err.Error())
+	}
+
+	fn, err := f.scaffoldFunction(c, name, event, int64(m), int64(ctx.Int("timeout")))
+	if err != nil {
+		return err
+	}
+	fn.Runtime = runtime
+	fn.Architecture = arch
+	f.log.Infof("Function \"%s\" created successfully.\n", name)
+	if c.ModuleRoot(name) != "" {
+		f.log.Warn("Run `ginger install` to resolve dependencies of new function.")
+	}
+	return nil
+}
+
+// scaffoldFunction creates function directory, main.go and event.json from templates.
+// New function runs on provided.al2023 runtime because go1.x runtime has been deprecated.
+func (f *Function) scaffoldFunction(c *config.Config, name, event string, memory, timeout int64) (*entity.Function, error) {
 	fn := &entity.Function{
-		Name:        name,
-		MemorySize:  int64(m),
-		Timeout:     int64(ctx.Int("timeout")),
-		Role:        c.DefaultLambdaRole,
-		Environment: make(map[string]*string),
+		Name:         name,
+		Runtime:      entity.RuntimeProvidedAl2023,
+		Architecture: entity.ArchitectureX86_64,
+		MemorySize:   memory,
+		Timeout:      timeout,
+		Role:         c.DefaultLambdaRole,
+		Environment:  make(map[string]*string),
 	}
 	fnPath := filepath.Join(c.FunctionPath, name)
 	if err := os.Mkdir(fnPath, 0755); err != nil {
-		return exception("Couldn't create directory: %s", fnPath)
+		return nil, exception("Couldn't create directory: %s", fnPath)
 	}
 	// Create main.go from template
 	if err := ioutil.WriteFile(
@@ -211,7 +282,7 @@ func (f *Function) createFunction(c *config.Config, ctx *args.Context) error {
 		f.buildTemplate(name, event),
 		0644,
 	); err != nil {
-		return exception("Create function error: %s", err.Error())
+		return nil, exception("Create function error: %s", err.Error())
 	}
 	// Create event.json from template
 	if err := ioutil.WriteFile(
@@ -219,12 +290,11 @@ func (f *Function) createFunction(c *config.Config, ctx *args.Context) error {
 		f.buildEventJson(event),
 		0644,
 	); err != nil {
-		return exception("Create event.json error: %s", err.Error())
+		return nil, exception("Create event.json error: %s", err.Error())
 	}
 
 	c.Queue[name] = fn
-	f.log.Infof("Function \"%s\" created successfully.\n", name)
-	return nil
+	return fn, nil
 }
 
 // buildTemplate makes lambda function boilterplace from supplied arguments.
@@ -425,7 +495,7 @@ func (f *Function) invokeFunction(c *config.Config, ctx *args.Context) error {
 		if strings.HasPrefix(src, "@") {
 			srcFile := src[1:]
 			if _, err := os.Stat(srcFile); err != nil {
-				return exception("Event source file %s doesn't exist.")
+				return exception("Event source file %s doesn't exist.", srcFile)
 			}
 			payload, _ = ioutil.ReadFile(srcFile)
 		} else {
@@ -463,14 +533,36 @@ func (f *Function) listFunction(c *config.Config, ctx *args.Context) error {
 	}
 	line := strings.Repeat("=", w)
 	fmt.Println(line)
-	fmt.Printf("%-36s %-16s %-16s %-4s\n", "name", "memory", "timeout", "deployed")
+	fmt.Printf("%-36s %-24s %-10s %-10s %-8s %-8s %-12s\n", "name", "runtime", "memory", "timeout", "deployed", "reserved", "provisioned")
 	fmt.Println(line)
 	for i, fn := range functions {
 		d := "no"
 		if fn.Arn != "" {
 			d = "yes"
 		}
-		fmt.Printf("%-36s %-16s %-16s %-4s\n", fn.Name, fmt.Sprintf("%d MB", fn.MemorySize), fmt.Sprintf("%d sec", fn.Timeout), d)
+		reserved := "-"
+		if fn.ReservedConcurrency != nil {
+			reserved = fmt.Sprint(*fn.ReservedConcurrency)
+		}
+		provisioned := []string{}
+		for _, a := range fn.Aliases {
+			if a.ProvisionedConcurrency > 0 {
+				provisioned = append(provisioned, fmt.Sprintf("%s:%d", a.Name, a.ProvisionedConcurrency))
+			}
+		}
+		if len(provisioned) == 0 {
+			provisioned = append(provisioned, "-")
+		}
+		fmt.Printf(
+			"%-36s %-24s %-10s %-10s %-8s %-8s %-12s\n",
+			fn.Name,
+			fn.GetRuntime()+"/"+fn.GetArchitecture(),
+			fmt.Sprintf("%d MB", fn.MemorySize),
+			fmt.Sprintf("%d sec", fn.Timeout),
+			d,
+			reserved,
+			strings.Join(provisioned, ","),
+		)
 		if i != len(functions)-1 {
 			fmt.Println(strings.Repeat("-", w))
 		}
@@ -534,11 +626,14 @@ func (f *Function) logFunction(c *config.Config, ctx *args.Context) error {
 // $ ginger function mount [options]
 // ```
 //
-// | option   | description                                                      |
-// |:--------:|:-----------------------------------------------------------------|
-// | --name   | Function name. If this option isn't supplied, ginger will ask it |
-// | --path   | Resource path. If this option isn't supplied, ginger will ask it |
-// | --method | Integration method                                               |
+// | option       | description                                                      |
+// |:------------:|:-----------------------------------------------------------------|
+// | --name       | Function name. If this option isn't supplied, ginger will ask it |
+// | --path       | Resource path. If this option isn't supplied, ginger will ask it |
+// | --method     | Integration method                                               |
+// | --authorizer | Authorizer name which protects the integration                   |
+//
+// To invoke function via alias, supply name with alias like `--name example:live`.
 //
 // <<< doc
 func (f *Function) mountFunction(c *config.Config, ctx *args.Context) error {
@@ -546,8 +641,12 @@ func (f *Function) mountFunction(c *config.Config, ctx *args.Context) error {
 	if name == "" {
 		name = c.ChooseFunction()
 	}
-	if _, err := c.LoadFunction(name); err != nil {
-		return exception("Function %s couldn't find in your project.", name)
+	fnName, qualifier := entity.SplitQualifier(name)
+	fn, err := c.LoadFunction(fnName)
+	if err != nil {
+		return exception("Function %s couldn't find in your project.", fnName)
+	} else if qualifier != "" && fn.GetAlias(qualifier) == nil {
+		return exception("Alias %s isn't declared in %s's Function.toml.", qualifier, fnName)
 	}
 
 	path := ctx.String("path")
@@ -566,9 +665,18 @@ func (f *Function) mountFunction(c *config.Config, ctx *args.Context) error {
 		method = util.ChooseMethod("ANY")
 	}
 
+	var authorizer *string
+	if au := ctx.String("authorizer"); au != "" {
+		if _, err := c.LoadAuthorizer(au); err != nil {
+			return exception("Authorizer %s couldn't find in your project.", au)
+		}
+		authorizer = &au
+	}
+
 	ig := rs.GetIntegration(method)
 	if ig == nil {
 		ig = entity.NewIntegration("lambda", name, rs.Path)
+		ig.Authorizer = authorizer
 		rs.AddIntegration(method, ig)
 		f.log.Infof("Function %s mouted to resource %s.\n", name, path)
 		return nil
@@ -650,9 +758,16 @@ func (f *Function) unmountFunction(c *config.Config, ctx *args.Context) error {
 // $ ginger function build [options]
 // ```
 //
-// | option   | description                                                      |
-// |:--------:|:-----------------------------------------------------------------|
-// | --name   | Target function name                                             |
+// | option     | description                                                      |
+// |:----------:|:-----------------------------------------------------------------|
+// | --name     | Target function name                                             |
+// | --artifact | Output path of deployable zip package                            |
+// | --no-cache | Build without build cache                                        |
+// | --stage    | Stage name which is used in `ldflags` template                   |
+//
+// If `--artifact` is supplied, ginger builds function for AWS Lambda and writes the same zip package as `ginger deploy function` uploads.
+// The package is reproducible: binary is built with `-trimpath` and empty build id, and zip entries have fixed modification time and permission.
+// So byte-identical sources produce byte-identical zip, and printed SHA-256 can be used for attestation or comparing with deployed `CodeSha256`.
 //
 // <<< doc
 func (f *Function) buildFunction(c *config.Config, ctx *args.Context) error {
@@ -660,11 +775,15 @@ func (f *Function) buildFunction(c *config.Config, ctx *args.Context) error {
 	if name == "" {
 		name = c.ChooseFunction()
 	}
-	if _, err := c.LoadFunction(name); err != nil {
+	fn, err := c.LoadFunction(name)
+	if err != nil {
 		return exception("Function %s couldn't find in your project.", name)
 	}
+	if artifact := ctx.String("artifact"); artifact != "" {
+		return f.buildArtifact(c, fn, artifact, ctx.String("stage"), !ctx.Has("no-cache"))
+	}
 
-	arguments := []string{"-o", filepath.Join(c.FunctionPath, name, name)}
+	arguments := localBuildArguments(fn, filepath.Join(c.FunctionPath, name, name))
 	if err := execGoCommand(context.Background(), c, name, "build", arguments); err != nil {
 		return exception("Failed to build %s function: %s\n", name, err.Error())
 	}
@@ -672,6 +791,39 @@ func (f *Function) buildFunction(c *config.Config, ctx *args.Context) error {
 	return nil
 }
 
+// buildArtifact builds function for AWS Lambda and writes deployable zip package.
+func (f *Function) buildArtifact(c *config.Config, fn *entity.Function, artifact, stage string, useCache bool) error {
+	if err := fn.ValidateRuntime(); err != nil {
+		return exception("Invalid runtime for function \"%s\": %s", fn.Name, err.Error())
+	}
+	tmpDir, err := ioutil.TempDir("", "ginger-artifact")
+	if err != nil {
+		return exception("Failed to create temporary directory: %s", err.Error())
+	}
+	defer os.RemoveAll(tmpDir)
+
+	b := newBuilder(c, tmpDir).withStage(stage)
+	if useCache {
+		b = b.withCache()
+	}
+	f.log.Printf("Building function %s for %s (%s)...\n", fn.Name, fn.GetRuntime(), fn.GetArchitecture())
+	if err := b.compileFunction(fn); err != nil {
+		return exception("Failed to build %s function: %s\n", fn.Name, err.Error())
+	}
+	buffer, err := archive(c, fn, filepath.Join(tmpDir, fn.Name))
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(artifact, buffer, 0644); err != nil {
+		return exception("Failed to write artifact: %s", err.Error())
+	}
+	sum := sha256.Sum256(buffer)
+	f.log.Infof("Artifact %s created successfully.\n", artifact)
+	f.log.Printf("SHA-256    : %s\n", hex.EncodeToString(sum[:]))
+	f.log.Printf("CodeSha256 : %s\n", base64.StdEncoding.EncodeToString(sum[:]))
+	return nil
+}
+
 // testFunction run tests with ginger's environment
 //
 // >>> doc
@@ -722,6 +874,9 @@ func (f *Function) testFunction(c *config.Config, ctx *args.Context) error {
 //
 // And, additional client context data also can provide. put (function-directory)/context.json and defined some JSON values.
 //
+// Function runs on both of `go1.x` RPC protocol and Lambda Runtime API which is used by `provided.al2` / `provided.al2023` custom runtime.
+// ginger serves Runtime API locally and passes its address as `AWS_LAMBDA_RUNTIME_API`, so `bootstrap` binary built with `lambda.norpc` tag also can run.
+//
 // ```
 // $ ginger function run [options]
 // ```
@@ -750,39 +905,46 @@ func (f *Function) runFunction(c *config.Config, ctx *args.Context) error {
 
 	// Build binary and put to temprary directory
 	bin := filepath.Join(tmpDir, name)
-	if err := execGoCommand(context.Background(), c, name, "build", []string{"-o", bin}); err != nil {
+	if err := execGoCommand(context.Background(), c, name, "build", localBuildArguments(fn, bin)); err != nil {
 		return exception("Failed to build %s binary: %s ", name, err.Error())
 	}
-	parentCtx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	source := loadEventSource(c, name, ctx.String("event"))
+	clientContext := loadClientContext(c, name)
 
-	// Start up Lambda RPC inside goroutine
-	// We run server by using built binary because `go run main.go` process cannot kill its process properly.
-	// The `go run main.go` makes temporary binary and run at `/var/folders/xxxxx/exe/main`,
-	// and if we kill process via cmd.Process.Kill(), then that process won't kill, so RPC process runs forever.
-	go func() {
-		f.log.Infof("Starting local %s Lambda...\n", name)
-		cmd := exec.CommandContext(parentCtx, bin)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		cmd.Env = buildEnv(map[string]string{
-			"_LAMBDA_SERVER_PORT": LAMBDARPCPORT,
-		})
-		// Append function specific environments
-		for k, v := range fn.Environment {
-			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, *v))
-		}
-		if err := cmd.Run(); err != nil {
-			fmt.Println(err)
+	runtime := newLocalRuntime(f.log)
+	f.log.Infof("Starting local %s Lambda...\n", name)
+	resp, err := runtime.invoke(fn, bin, source, clientContext)
+	f.log.Infof("Shutting down local %s Lambda...\n", name)
+	runtime.shutdown()
+	if err != nil {
+		return exception("Failed to invoke local Lambda: %s", err.Error())
+	}
+	return f.printLocalResponse(resp)
+}
+
+// printLocalResponse prints local lambda response, and returns error if function responded error.
+func (f *Function) printLocalResponse(resp *messages.InvokeResponse) error {
+	if resp.Error != nil {
+		f.log.Errorf("Lambda responded error:\nType: %s\nMessage: %s\n", resp.Error.Type, resp.Error.Message)
+		if len(resp.Error.StackTrace) > 0 {
+			f.log.Warn("StackTrace")
+			for _, frame := range resp.Error.StackTrace {
+				f.log.Warnf("%s at line %d: %s\n", frame.Path, frame.Line, frame.Label)
+			}
 		}
-		f.log.Infof("Shutting down local %s Lambda...\n", name)
-	}()
+		return exception("Failed to run lambda function")
+	} else if resp.Payload != nil {
+		f.log.Printf("payload received:\n%s\n", string(resp.Payload))
+	}
+	return nil
+}
 
-	// Factory source JSON
+// loadEventSource loads event payload for local invocation.
+// If event isn't supplied, try to retrieve function directory's event.json,
+// and if event supplied as "event template name", use template JSON from compiled assets.
+func loadEventSource(c *config.Config, name, event string) []byte {
 	source := []byte("{}")
-	event := ctx.String("event")
 	if event == "" {
-		// If event isn't supplied, try to retrieve function directory's event.json
 		eventFile := filepath.Join(c.FunctionPath, name, eventSourceFileName)
 		if _, err := os.Stat(eventFile); err == nil {
 			if buf, err := ioutil.ReadFile(eventFile); err == nil {
@@ -790,13 +952,15 @@ func (f *Function) runFunction(c *config.Config, ctx *args.Context) error {
 			}
 		}
 	} else if src, err := assets.Assets.Open(fmt.Sprintf("/events/%s.json", event)); err == nil {
-		// If event supplied as "event template name", use template JSON from compiled assets
 		buf := new(bytes.Buffer)
 		io.Copy(buf, src)
 		source = buf.Bytes()
 	}
+	return source
+}
 
-	// Use context data if exsits
+// loadClientContext loads client context data from function directory's context.json if exists.
+func loadClientContext(c *config.Config, name string) []byte {
 	clientContext := []byte{}
 	contextFile := filepath.Join(c.FunctionPath, name, clientContextFileName)
 	if _, err := os.Stat(contextFile); err == nil {
@@ -804,24 +968,535 @@ func (f *Function) runFunction(c *config.Config, ctx *args.Context) error {
 			clientContext = buf
 		}
 	}
+	return clientContext
+}
+
+// watchFunction runs function locally, and rebuilds and restarts it on source changes.
+//
+// >>> doc
+//
+// ## Watch function
+//
+// Run Lambda function locally, and reload it when `.go` files are changed.
+// ginger watches function directory and `local_packages` directories.
+//
+// ```
+// $ ginger function watch [options]
+// ```
+//
+// | option   | description                                                          |
+// |:--------:|:---------------------------------------------------------------------|
+// | --name   | Target function name                                                 |
+// | --event  | Event payload which is used on `--invoke`. see `ginger function run` |
+// | --invoke | Invoke function every time function is reloaded                      |
+//
+// Build errors are displayed and ginger keeps watching, so fix the code and save it again.
+//
+// <<< doc
+func (f *Function) watchFunction(c *config.Config, ctx *args.Context) error {
+	name := ctx.String("name")
+	if name == "" {
+		name = c.ChooseFunction()
+	}
+	fn, err := c.LoadFunction(name)
+	if err != nil {
+		return exception("Function %s couldn't find in your project.", name)
+	}
 
-	// Wait until lambda RPC server has been started (maybe a second is enough)
-	time.Sleep(1 * time.Second)
-	resp, err := execLambdaRPC(fn.Timeout, source, clientContext)
+	tmpDir, err := ioutil.TempDir("", "ginger-local-watch")
 	if err != nil {
-		return exception("Failed to call Lambda RPC: %s", err.Error())
+		return exception("Failed to create temporary directory: %s", err.Error())
 	}
-	if resp.Error != nil {
-		f.log.Errorf("Lambda responded error:\nType: %s\nMessage: %s\n", resp.Error.Type, resp.Error.Message)
-		if len(resp.Error.StackTrace) > 0 {
-			f.log.Warn("StackTrace")
-			for _, frame := range resp.Error.StackTrace {
-				f.log.Warnf("%s at line %d: %s\n", frame.Path, frame.Line, frame.Label)
+	defer os.RemoveAll(tmpDir)
+	bin := filepath.Join(tmpDir, name)
+
+	runtime := newLocalRuntime(f.log)
+	var mu sync.Mutex
+	reload := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		f.log.Infof("Shutting down local %s Lambda...\n", name)
+		runtime.reload(name)
+		f.log.Printf("Building function %s...\n", name)
+		if err := execGoCommand(context.Background(), c, name, "build", localBuildArguments(fn, bin)); err != nil {
+			f.log.Errorf("Failed to build %s binary: %s. Waiting for changes...\n", name, err.Error())
+			return
+		}
+		f.log.Infof("Starting local %s Lambda...\n", name)
+		if err := runtime.warm(fn, bin); err != nil {
+			f.log.Error(err.Error())
+			return
+		}
+		if !ctx.Has("invoke") {
+			return
+		}
+		// Reload event source because it may be changed
+		resp, err := runtime.invoke(fn, bin, loadEventSource(c, name, ctx.String("event")), loadClientContext(c, name))
+		if err != nil {
+			f.log.Errorf("Failed to invoke local Lambda: %s\n", err.Error())
+			return
+		}
+		f.printLocalResponse(resp)
+	}
+
+	dirs := append([]string{filepath.Join(c.FunctionPath, name)}, localPackageDirs(c)...)
+	watcher := newFileWatcher(dirs)
+	reload()
+
+	parentCtx, cancel := context.WithCancel(context.Background())
+	go watcher.watch(parentCtx, func(changed []string) {
+		f.log.Warnf("Detected changes: %s\n", strings.Join(changed, ", "))
+		reload()
+	})
+	f.log.Warnf("Watching %s for changes. Press Ctrl+C to stop.\n", strings.Join(dirs, ", "))
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+	<-ch
+	cancel()
+	mu.Lock()
+	defer mu.Unlock()
+	runtime.shutdown()
+	return nil
+}
+
+// migrateRuntime migrates go1.x function to custom runtime.
+//
+// >>> doc
+//
+// ## Migrate function runtime
+//
+// Migrate function from deprecated `go1.x` runtime to `provided.al2023` or `provided.al2` custom runtime.
+// If `--name` isn't supplied, all functions which run on `go1.x` are migrated.
+//
+// ```
+// $ ginger function migrate-runtime [options]
+// ```
+//
+// | option    | description                                                                   |
+// |:---------:|:------------------------------------------------------------------------------|
+// | --name    | Target function name                                                          |
+// | --runtime | Runtime to migrate. enable values are `provided.al2023` or `provided.al2`. default is `provided.al2023` |
+// | --arch    | Architecture to migrate. enable values are `x86_64` or `arm64`. default is current architecture |
+//
+// Custom runtime function is built as `bootstrap` executable with `lambda.norpc` tag, so function have to use aws-lambda-go which supports Lambda Runtime API.
+// Migration only changes `Function.toml`, run `ginger deploy function` to apply to AWS Lambda.
+//
+// <<< doc
+func (f *Function) migrateRuntime(c *config.Config, ctx *args.Context) error {
+	runtime := ctx.String("runtime")
+	if runtime == "" {
+		runtime = entity.RuntimeProvidedAl2023
+	}
+	if runtime == entity.RuntimeGo1x {
+		return exception("Cannot migrate to deprecated %s runtime.", entity.RuntimeGo1x)
+	}
+
+	targets := []*entity.Function{}
+	if name := ctx.String("name"); name != "" {
+		fn, err := c.LoadFunction(name)
+		if err != nil {
+			return exception("Function %s couldn't find in your project.", name)
+		}
+		targets = append(targets, fn)
+	} else {
+		functions, err := c.LoadAllFunctions()
+		if err != nil {
+			return exception("Failed to list functions: %s", err.Error())
+		}
+		for _, fn := range functions {
+			if fn.GetRuntime() == entity.RuntimeGo1x {
+				targets = append(targets, fn)
 			}
 		}
-		return exception("Failed to run lambda function")
-	} else if resp.Payload != nil {
-		f.log.Printf("payload received:\n%s\n", string(resp.Payload))
+	}
+	if len(targets) == 0 {
+		f.log.Warn("No functions to migrate.")
+		return nil
+	}
+
+	for _, fn := range targets {
+		migrated := *fn
+		migrated.Runtime = runtime
+		if arch := ctx.String("arch"); arch != "" {
+			migrated.Architecture = arch
+		} else {
+			migrated.Architecture = fn.GetArchitecture()
+		}
+		if err := migrated.ValidateRuntime(); err != nil {
+			return exception("Failed to migrate function %s: %s", fn.Name, err.Error())
+		}
+		f.log.Printf(
+			"Function %s: %s (%s) -> %s (%s)\n",
+			fn.Name,
+			fn.GetRuntime(),
+			fn.GetArchitecture(),
+			migrated.GetRuntime(),
+			migrated.GetArchitecture(),
+		)
+		fn.Runtime = migrated.Runtime
+		fn.Architecture = migrated.Architecture
+	}
+	f.log.Infof("%d function(s) migrated successfully.\n", len(targets))
+	f.log.Warn("Run `ginger deploy function` to apply new runtime to AWS Lambda.")
+	return nil
+}
+
+// promoteFunction points function alias to published version.
+//
+// >>> doc
+//
+// ## Promote function
+//
+// Point function alias to published version, and record the version to alias in `Function.toml`.
+// If alias isn't declared in `Function.toml`, it is declared as well.
+//
+// ```
+// $ ginger function promote [options]
+// ```
+//
+// | option    | description                                                               |
+// |:---------:|:--------------------------------------------------------------------------|
+// | --name    | Function name. If this option isn't supplied, ginger will ask it          |
+// | --alias   | Alias name                                                                |
+// | --version | Published version. If this option isn't supplied, use latest deployed one |
+//
+// <<< doc
+func (f *Function) promoteFunction(c *config.Config, ctx *args.Context) error {
+	name := ctx.String("name")
+	if name == "" {
+		name = c.ChooseFunction()
+	}
+	fn, err := c.LoadFunction(name)
+	if err != nil {
+		return exception("Function %s couldn't find in your project.", name)
+	}
+	alias := ctx.String("alias")
+	if alias == "" {
+		return exception("Alias name is required. Please supply --alias option.")
+	}
+	version := ctx.String("version")
+	if version == "" {
+		if fn.Version == "" {
+			return exception("Function %s hasn't been deployed yet.", name)
+		}
+		version = fn.Version
+	}
+
+	lambda := request.NewLambda(c)
+	if !lambda.VersionExists(fn.Name, version) {
+		return exception("Version %s of %s couldn't find in AWS.", version, name)
+	}
+	if err := lambda.PutAlias(fn.Name, alias, version); err != nil {
+		return exception("Failed to promote %s: %s", name, err.Error())
+	}
+	fn.SetAlias(alias, version)
+	f.log.Infof("Alias %s of %s has been promoted to version %s.\n", alias, name, version)
+	return nil
+}
+
+// rollbackFunction rolls function back to previous deployment.
+//
+// >>> doc
+//
+// ## Roll back function
+//
+// Roll function back to previous deployment which is recorded in deployment history.
+// Deployment history is recorded in `.ginger/history/[function name].toml` on each deployment,
+// with version, `CodeSha256`, git commit, timestamp, S3 artifact location and configuration snapshot.
+//
+// ```
+// $ ginger function rollback [options]
+// ```
+//
+// | option  | description                                                                            |
+// |:-------:|:---------------------------------------------------------------------------------------|
+// | --name  | Function name. If this option isn't supplied, ginger will ask it                       |
+// | --to    | Version to roll back. If this option isn't supplied, use previous deployment           |
+// | --alias | Alias to roll back. If this option isn't supplied, use alias of `[deployment]` section |
+//
+// If alias is available, alias is pointed to the version and pinned in `Function.toml`.
+// Otherwise, package of the version is deployed again from S3 with recorded configuration, and new version is published.
+// In this case, only `version` in `Function.toml` is updated, so next deployment applies local configuration again.
+// Note that the package is available only if it was uploaded via S3 (see `ginger config --code-upload`).
+//
+// <<< doc
+func (f *Function) rollbackFunction(c *config.Config, ctx *args.Context) error {
+	name := ctx.String("name")
+	if name == "" {
+		name = c.ChooseFunction()
+	}
+	fn, err := c.LoadFunction(name)
+	if err != nil {
+		return exception("Function %s couldn't find in your project.", name)
+	}
+	h, err := c.LoadHistory(fn.Name)
+	if err != nil {
+		return exception("Failed to load deployment history: %s", err.Error())
+	}
+
+	lambda := request.NewLambda(c)
+	alias := ctx.String("alias")
+	if alias == "" && fn.Deployment != nil {
+		alias = fn.Deployment.Alias
+	}
+	current := fn.Version
+	if alias != "" {
+		a, err := lambda.GetAlias(fn.Name, alias)
+		if err != nil {
+			return exception("Alias %s of %s couldn't find in AWS.", alias, name)
+		}
+		current = *a.FunctionVersion
+	} else if current == "" && len(h.Deployments) > 0 {
+		current = h.Deployments[len(h.Deployments)-1].Version
+	}
+
+	var target *entity.Deployment
+	if to := ctx.String("to"); to != "" {
+		if target = h.Find(to); target == nil {
+			return exception("Version %s isn't found in deployment history of %s.", to, name)
+		}
+	} else if target = h.Previous(current); target == nil {
+		return exception("Previous deployment of version %s isn't found in deployment history of %s.", current, name)
+	}
+	if target.Version == current {
+		return exception("Function %s is already on version %s.", name, current)
+	}
+
+	if alias != "" {
+		if !lambda.VersionExists(fn.Name, target.Version) {
+			return exception("Version %s of %s couldn't find in AWS.", target.Version, name)
+		}
+		if err := lambda.PutAlias(fn.Name, alias, target.Version); err != nil {
+			return exception("Failed to roll back %s: %s", name, err.Error())
+		}
+		fn.SetAlias(alias, target.Version)
+		f.log.Infof("Alias %s of %s has been rolled back to version %s.\n", alias, name, target.Version)
+		f.log.Warnf("Alias %s is pinned to version %s in Function.toml.\n", alias, target.Version)
+		return nil
+	}
+
+	// Deploy archived package again with configuration snapshot
+	if !target.HasArtifact() {
+		return exception(
+			"Package of version %s wasn't uploaded to S3. Roll back alias with --alias option, or use `ginger config --code-upload s3` to keep packages.",
+			target.Version,
+		)
+	} else if target.Config == nil {
+		return exception("Configuration snapshot of version %s isn't recorded.", target.Version)
+	}
+	snapshot := *target.Config
+	snapshot.Name = fn.Name
+	if snapshot.Role == "" {
+		snapshot.Role = c.DefaultLambdaRole
+	}
+	f.log.Printf("Deploying package of version %s from s3://%s/%s...\n", target.Version, target.S3Bucket, target.S3Key)
+	result, err := lambda.RedeployArtifact(&snapshot, target.S3Bucket, target.S3Key, target.S3ObjectVersion)
+	if err != nil {
+		return exception("Failed to roll back %s: %s", name, err.Error())
+	}
+	fn.Version = *result.Version
+	snapshot.Version = *result.Version
+	if err := recordDeployment(c, &snapshot, result, nil, target.Version); err != nil {
+		f.log.Warnf("Failed to record deployment history of %s: %s\n", name, err.Error())
+	}
+	f.log.Infof("Function %s has been rolled back to version %s as version %s.\n", name, target.Version, *result.Version)
+	f.log.Warnf("Only version in Function.toml is updated to %s, so next deployment applies local configuration.\n", *result.Version)
+	return nil
+}
+
+// historyFunction shows deployment history of function.
+//
+// >>> doc
+//
+// ## Show deployment history
+//
+// Show deployment history of function.
+//
+// ```
+// $ ginger function history [options]
+// ```
+//
+// | option | description                                                      |
+// |:------:|:-----------------------------------------------------------------|
+// | --name | Function name. If this option isn't supplied, ginger will ask it |
+//
+// <<< doc
+func (f *Function) historyFunction(c *config.Config, ctx *args.Context) error {
+	name := ctx.String("name")
+	if name == "" {
+		name = c.ChooseFunction()
+	}
+	fn, err := c.LoadFunction(name)
+	if err != nil {
+		return exception("Function %s couldn't find in your project.", name)
+	}
+	h, err := c.LoadHistory(fn.Name)
+	if err != nil {
+		return exception("Failed to load deployment history: %s", err.Error())
+	} else if len(h.Deployments) == 0 {
+		f.log.Warnf("Function %s hasn't been deployed yet.\n", name)
+		return nil
+	}
+
+	t, err := tty.Open()
+	if err != nil {
+		return exception("Couldn't open tty")
+	}
+	defer t.Close()
+	w, _, err := t.Size()
+	if err != nil {
+		return exception("Couldn't get tty size")
+	}
+	line := strings.Repeat("=", w)
+	fmt.Println(line)
+	fmt.Printf("%-8s %-20s %-10s %-14s %-8s %s\n", "version", "deployed at", "commit", "code sha256", "artifact", "note")
+	fmt.Println(line)
+	for i := len(h.Deployments) - 1; i >= 0; i-- {
+		d := h.Deployments[i]
+		artifact := "inline"
+		if d.HasArtifact() {
+			artifact = "s3"
+		}
+		notes := []string{}
+		if d.Version == fn.Version {
+			notes = append(notes, "latest")
+		}
+		for _, a := range fn.Aliases {
+			if a.Version == d.Version {
+				notes = append(notes, "alias:"+a.Name)
+			}
+		}
+		if d.RollbackOf != "" {
+			notes = append(notes, "rollback of "+d.RollbackOf)
+		}
+		fmt.Printf(
+			"%-8s %-20s %-10s %-14s %-8s %s\n",
+			d.Version,
+			d.DeployedAt.Format("2006-01-02 15:04:05"),
+			shorten(d.GitCommit, 8),
+			shorten(d.CodeSha256, 12),
+			artifact,
+			strings.Join(notes, ", "),
+		)
 	}
 	return nil
 }
+
+// Seconds to wait for messages of dead letter queue.
+// Redrive waits the maximum long polling time to stop only when the queue is surely empty.
+const (
+	dlqPeekWaitTime    = 5
+	dlqRedriveWaitTime = 20
+)
+
+// dlqFunction peeks or redrives messages in dead letter queue of function.
+//
+// >>> doc
+//
+// ## Dead letter queue
+//
+// Peek messages in dead letter queue of function, or invoke function asynchronously with them again.
+// SQS queue of `dead_letter_queue` in `[async]` section is used, or `on_failure` destination if it is SQS queue.
+//
+// ```
+// $ ginger function dlq [options]
+// ```
+//
+// | option    | description                                                                                                 |
+// |:---------:|:------------------------------------------------------------------------------------------------------------|
+// | --name    | Function name. If this option isn't supplied, ginger will ask it. Alias can be supplied like `example:live` |
+// | --redrive | Invoke function with messages and delete them from queue. If not supplied, only peek messages               |
+//
+// <<< doc
+func (f *Function) dlqFunction(c *config.Config, ctx *args.Context) error {
+	name := ctx.String("name")
+	if name == "" {
+		name = c.ChooseFunction()
+	}
+	fnName, _ := entity.SplitQualifier(name)
+	fn, err := c.LoadFunction(fnName)
+	if err != nil {
+		return exception("Function %s couldn't find in your project.", fnName)
+	}
+	if fn.Async == nil || fn.Async.FailureQueue() == "" {
+		return exception("Function %s doesn't have SQS dead letter queue or on-failure destination.", fnName)
+	}
+
+	sqs := request.NewSQS(c)
+	url, err := sqs.GetQueueUrl(fn.Async.FailureQueue())
+	if err != nil {
+		return exception("Failed to get queue url: %s", err.Error())
+	}
+
+	if !ctx.Has("redrive") {
+		// Zero visibility timeout keeps messages visible
+		messages, err := sqs.ReceiveMessages(url, 10, 0, dlqPeekWaitTime)
+		if err != nil {
+			return exception("Failed to receive messages: %s", err.Error())
+		} else if len(messages) == 0 {
+			f.log.Info("No messages found in queue.")
+			return nil
+		}
+		for _, m := range messages {
+			f.log.Warnf("Message %s\n", aws.StringValue(m.MessageId))
+			for _, key := range []string{"RequestID", "ErrorCode", "ErrorMessage"} {
+				if v, ok := m.MessageAttributes[key]; ok {
+					f.log.Printf("%s: %s\n", key, aws.StringValue(v.StringValue))
+				}
+			}
+			fmt.Println(aws.StringValue(m.Body))
+		}
+		return nil
+	}
+
+	lambda := request.NewLambda(c)
+	redriven := 0
+	failed := 0
+	for {
+		messages, err := sqs.ReceiveMessages(url, 10, 60, dlqRedriveWaitTime)
+		if err != nil {
+			return exception("Failed to receive messages: %s", err.Error())
+		} else if len(messages) == 0 {
+			break
+		}
+		for _, m := range messages {
+			if err := lambda.InvokeFunctionAsync(name, redrivePayload(aws.StringValue(m.Body))); err != nil {
+				f.log.Errorf("Failed to redrive message %s: %s\n", aws.StringValue(m.MessageId), err.Error())
+				failed++
+				continue
+			}
+			if err := sqs.DeleteMessage(url, m); err != nil {
+				f.log.Warnf("Message %s was redriven but couldn't be deleted: %s\n", aws.StringValue(m.MessageId), err.Error())
+			}
+			redriven++
+		}
+	}
+	f.log.Infof("%d message(s) redriven to %s.\n", redriven, name)
+	if failed > 0 {
+		return exception("%d message(s) failed to redrive, they are kept in queue.", failed)
+	}
+	return nil
+}
+
+// redrivePayload extracts original event from dead letter message.
+// Dead letter queue message body is the event itself,
+// but on-failure destination message is the invocation record which wraps event as requestPayload.
+func redrivePayload(body string) []byte {
+	record := struct {
+		RequestContext json.RawMessage `json:"requestContext"`
+		RequestPayload json.RawMessage `json:"requestPayload"`
+	}{}
+	if err := json.Unmarshal([]byte(body), &record); err == nil && record.RequestContext != nil && record.RequestPayload != nil {
+		return record.RequestPayload
+	}
+	return []byte(body)
+}
+
+// shorten cuts string to the length.
+func shorten(s string, length int) string {
+	if len(s) > length {
+		return s[0:length]
+	}
+	return s
+}
@@ -0,0 +1,42 @@
+package command
+
+import (
+	"testing"
+)
+
+func TestRedrivePayload(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		expect string
+	}{
+		{
+			name:   "dead letter queue message is the event",
+			body:   `{"id":1}`,
+			expect: `{"id":1}`,
+		},
+		{
+			name:   "on-failure destination record is unwrapped",
+			body:   `{"version":"1.0","requestContext":{"condition":"RetriesExhausted"},"requestPayload":{"id":1},"responsePayload":null}`,
+			expect: `{"id":1}`,
+		},
+		{
+			name:   "event which has requestPayload only is kept",
+			body:   `{"requestPayload":{"id":1}}`,
+			expect: `{"requestPayload":{"id":1}}`,
+		},
+		{
+			name:   "non-JSON message is kept",
+			body:   "plain text",
+			expect: "plain text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := string(redrivePayload(tt.body)); actual != tt.expect {
+				t.Errorf("expected %s, got %s", tt.expect, actual)
+			}
+		})
+	}
+}
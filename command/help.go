@@ -38,14 +38,18 @@ Usage:
   $ ginger [subcommand] [options]
 
 SubCommands:
-  init      : Initialize project
-  install   : Install ginger dependencies
-  config    : Update project configurations
-  function  : Manage Go runtime Lambda functions
-  scheduler : Manage CloudWatchEvent scheduler
-  resource  : Manage APIGateway resources
-  stage     : Manage APIGateway stages
-  deploy    : Deploy function or api resource
+  init       : Initialize project
+  install    : Install ginger dependencies
+  config     : Update project configurations
+  function   : Manage Go runtime Lambda functions
+  layer      : Manage Lambda layers
+  scheduler  : Manage CloudWatchEvent scheduler
+  resource   : Manage APIGateway resources
+  stage      : Manage APIGateway stages
+  authorizer : Manage APIGateway authorizers
+  apikey     : Manage APIGateway API keys
+  deploy     : Deploy function or api resource
+  serve      : Run local API Gateway emulator
 
 Options:
   -h, --help: Show help
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"path/filepath"
 
@@ -59,21 +60,16 @@ func (i *Init) Help() string {
 // $ ginger init [options]
 // ```
 //
-// If you want to use (probably almost case yes) external Go package, we suggest you should put project directory under the `$GOPATH` to enable to detect vendor tree.
-//
-// For example:
-//
-// ```
-// cd $GOATH/src/github.com/your/project
-// ginger init
-// ```
+// | option   | description                                                                    |
+// |:--------:|:-------------------------------------------------------------------------------|
+// | --module | Module path of generated `go.mod`. default is derived from `$GOPATH` or project name |
 //
 // The ginger init command will work as following:
 //
 // - Create `Ginger.toml` file which is used for project configuration
 // - Create `functions` directory which is used for function management
 // - Create `stages` directory which is used for stage variable management
-// - Create `.ginger`  directory which is used for put dependency packags. Those packages will be loaded on deploy phase..
+// - Create `go.mod` file on project root via `go mod init` if not exists. Functions are built as Go modules
 // - Install dependency packages.
 //
 // Note that the `Ginger.toml` is readable and configurable, but almost values are added or updated via subcommands.
@@ -173,11 +169,34 @@ func (i *Init) Run(ctx *args.Context) error {
 		c.Region = region
 	}
 	c.Write()
+	if err := i.initModule(c, ctx.String("module")); err != nil {
+		i.log.Warnf("Failed to create go.mod: %s\n", err.Error())
+	}
 	NewInstall().Run(ctx)
 	i.log.Info("ginger initalized successfully!")
 	return nil
 }
 
+// initModule creates go.mod on project root if not exists.
+// If module path isn't supplied, use import path under the GOPATH, or project name.
+func (i *Init) initModule(c *config.Config, modulePath string) error {
+	if _, err := os.Stat(filepath.Join(c.Root, "go.mod")); err == nil {
+		i.log.Print("go.mod found. Use existing module.")
+		return nil
+	}
+	if modulePath == "" {
+		modulePath = c.ProjectName
+		for _, p := range filepath.SplitList(os.Getenv("GOPATH")) {
+			if rel, err := filepath.Rel(filepath.Join(p, "src"), c.Root); err == nil && !strings.HasPrefix(rel, "..") {
+				modulePath = filepath.ToSlash(rel)
+				break
+			}
+		}
+	}
+	i.log.Printf("Create go.mod as module %s\n", modulePath)
+	return execModCommand(c.Root, "mod", "init", modulePath)
+}
+
 // Ensure .keep file and create if not exist
 // The .keep file is needed for adding directory to git
 func (i *Init) ensureKeepFile(dir string) {
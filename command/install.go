@@ -8,6 +8,7 @@ import (
 
 	"go/parser"
 	"go/token"
+	"io/ioutil"
 	"os/exec"
 	"path/filepath"
 
@@ -19,11 +20,15 @@ import (
 )
 
 // findDependencyPackages finds import packages in your lambda functions.
-// walk functions directory recursively, and add to set.
-func findDependencyPackages(root string, localPackages []string) ([]*strset.Set, error) {
-	files, err := listFunctionScriptFiles(root)
-	if err != nil {
-		return nil, err
+// walk function directories recursively, and add to set.
+func findDependencyPackages(roots []string, localPackages []string) ([]*strset.Set, error) {
+	files := []string{}
+	for _, root := range roots {
+		fs, err := listFunctionScriptFiles(root)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, fs...)
 	}
 
 	// aws-lamda-go is required as default
@@ -105,6 +110,33 @@ func (i *Install) Help() string {
 	return "No Help"
 }
 
+// Run the install command.
+//
+// >>> doc
+//
+// ## Install dependencies
+//
+// Install dependency packages for build lambda function.
+//
+// ```
+// $ ginger install [options]
+// ```
+//
+// | option   | description                              |
+// |:--------:|:-----------------------------------------|
+// | --update | Update dependency packages to the latest |
+//
+// This command is run automatically on initialize, but if you checkout project after initialize,
+// You can install dependency packages via this command.
+//
+// If project root or function directory has `go.mod`, function is managed by Go modules.
+// ginger runs `go mod tidy` and `go mod download` for each module, and builds function in module mode without touching `GOPATH`.
+// The `-mod` build flag is `vendor` if module has vendor directory, otherwise `readonly`. You can change it by `go_mod_flag` in `Ginger.toml`.
+// If function module imports `local_packages` which has its own `go.mod`, ginger adds `replace` directive to the function's `go.mod`.
+//
+// Otherwise, ginger detects imports from your *.go file and install inside `.ginger` directory as `GOPATH`.
+//
+// <<< doc
 func (i *Install) Run(ctx *args.Context) error {
 	c := config.Load()
 	if !c.Exists() {
@@ -114,6 +146,28 @@ func (i *Install) Run(ctx *args.Context) error {
 
 	i.log.Print("Install function dependencies.")
 
+	modules := c.ModuleDirs()
+	for _, dir := range modules {
+		if err := i.installModule(c, dir, ctx.Has("update")); err != nil {
+			i.log.Errorf("Failed to resolve module %s: %s\n", dir, err.Error())
+			return err
+		}
+	}
+
+	// Functions which are not managed by Go modules are installed to GOPATH
+	legacy := []string{}
+	if files, err := ioutil.ReadDir(c.FunctionPath); err == nil {
+		for _, f := range files {
+			if f.IsDir() && c.ModuleRoot(f.Name()) == "" {
+				legacy = append(legacy, filepath.Join(c.FunctionPath, f.Name()))
+			}
+		}
+	}
+	if len(modules) > 0 && len(legacy) == 0 {
+		i.log.Info("Dependencies resolved successfully.")
+		return nil
+	}
+
 	if _, err := os.Stat(c.LibPath); err != nil {
 		i.log.Printf("Create library directory: %s\n", c.LibPath)
 		if err := os.Mkdir(c.LibPath, 0755); err != nil {
@@ -127,7 +181,7 @@ func (i *Install) Run(ctx *args.Context) error {
 		localPackages = c.LocalPackages
 	}
 
-	deps, err := findDependencyPackages(c.FunctionPath, localPackages)
+	deps, err := findDependencyPackages(legacy, localPackages)
 	if err != nil {
 		i.log.Errorf("Find dependency error: %s\n", err.Error())
 		return err
@@ -161,22 +215,6 @@ func (i *Install) Run(ctx *args.Context) error {
 }
 
 // installDependencies installs dependencies via "go get".
-//
-// >>> doc
-//
-// ## Install dependencies
-//
-// Install dependency packages for build lambda function.
-//
-// ```
-// $ ginger install
-// ```
-//
-// This command is run automatically on initialize, but if you checkout project after initialize,
-// You can install dependency packages via this command.
-// ginger detects imports from your *.go file and install inside `.ginger` directory.
-//
-// <<< doc
 func (i *Install) installDependencies(pkg, tmpDir string, isUpdate bool) error {
 	buffer := new(bytes.Buffer)
 	var cmdArgs []string
@@ -199,3 +237,112 @@ func (i *Install) installDependencies(pkg, tmpDir string, isUpdate bool) error {
 	}
 	return nil
 }
+
+// installModule resolves dependencies of Go module via "go mod tidy" and "go mod download".
+func (i *Install) installModule(c *config.Config, dir string, isUpdate bool) error {
+	name := config.ReadModulePath(dir)
+	if name == "" {
+		name = dir
+	}
+	i.log.Printf("Resolving module %s...\n", name)
+
+	// Function module has to refer local packages via replace directive
+	if dir != c.Root {
+		for _, pkg := range c.LocalPackages {
+			if err := i.replaceLocalPackage(c, dir, pkg); err != nil {
+				return err
+			}
+		}
+	}
+
+	commands := [][]string{}
+	if isUpdate {
+		commands = append(commands, []string{"get", "-u", "./..."})
+	}
+	commands = append(commands, []string{"mod", "tidy"}, []string{"mod", "download"})
+	for _, cmdArgs := range commands {
+		if err := execModCommand(dir, cmdArgs...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceLocalPackage adds replace directive for local package if the module imports it.
+func (i *Install) replaceLocalPackage(c *config.Config, dir, pkg string) error {
+	pkgDir := localPackageDir(c, pkg)
+	if pkgDir == "" || !importsPackage(dir, pkg) {
+		return nil
+	}
+	modulePath := config.ReadModulePath(pkgDir)
+	if modulePath == "" {
+		i.log.Warnf("Local package %s doesn't have go.mod, so couldn't replace it.\n", pkg)
+		return nil
+	}
+	rel, err := filepath.Rel(dir, pkgDir)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(rel, "..") {
+		rel = "./" + rel
+	}
+	i.log.Printf("Replace %s => %s\n", modulePath, rel)
+	return execModCommand(dir, "mod", "edit", "-replace="+modulePath+"="+filepath.ToSlash(rel))
+}
+
+// execModCommand runs go command in module mode at the directory.
+func execModCommand(dir string, cmdArgs ...string) error {
+	buffer := new(bytes.Buffer)
+	cmd := exec.Command("go", cmdArgs...)
+	cmd.Dir = dir
+	cmd.Env = buildEnv(map[string]string{
+		"GO111MODULE": "on",
+	})
+	cmd.Stdout = buffer
+	cmd.Stderr = buffer
+	if err := cmd.Run(); err != nil {
+		return errors.New(string(buffer.Bytes()))
+	}
+	return nil
+}
+
+// importsPackage returns true if any .go file under the directory imports the package.
+func importsPackage(dir, pkg string) bool {
+	files, err := listFunctionScriptFiles(dir)
+	if err != nil {
+		return false
+	}
+	for _, f := range files {
+		t := token.NewFileSet()
+		ast, err := parser.ParseFile(t, f, nil, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		for _, i := range ast.Imports {
+			if p := strings.Trim(i.Path.Value, `"`); p == pkg || strings.HasPrefix(p, pkg+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// localPackageDir resolves source directory of local package.
+// Local package is found in project root, GOPATH or ginger's library path.
+func localPackageDir(c *config.Config, pkg string) string {
+	roots := []string{c.Root}
+	if gopath := os.Getenv("GOPATH"); gopath != "" {
+		for _, p := range filepath.SplitList(gopath) {
+			roots = append(roots, filepath.Join(p, "src"))
+		}
+	}
+	roots = append(roots, filepath.Join(c.LibPath, "src"))
+
+	for _, root := range roots {
+		dir := filepath.Join(root, pkg)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return ""
+}
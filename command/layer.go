@@ -0,0 +1,431 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/mattn/go-tty"
+	"github.com/ysugimoto/go-args"
+
+	"github.com/ysugimoto/ginger/config"
+	"github.com/ysugimoto/ginger/entity"
+	"github.com/ysugimoto/ginger/input"
+	"github.com/ysugimoto/ginger/logger"
+	"github.com/ysugimoto/ginger/request"
+)
+
+const (
+	LAYERCREATE  = "create"
+	LAYERBUILD   = "build"
+	LAYERPUBLISH = "publish"
+	LAYERLIST    = "list"
+	LAYERHELP    = "help"
+)
+
+// Layer is the struct of AWS Lambda layer management command.
+// This struct will be dispatched on "ginger layer" subcommand.
+type Layer struct {
+	Command
+	log *logger.Logger
+}
+
+func NewLayer() *Layer {
+	return &Layer{
+		log: logger.WithNamespace("ginger.layer"),
+	}
+}
+
+// Show layer command help.
+func (l *Layer) Help() string {
+	return commandHeader() + `
+layer - (AWS Lambda) layer management command.
+
+Usage:
+  $ ginger layer [operation] [options]
+
+Operation:
+  create  : Create new layer
+  build   : Build layer package
+  publish : Publish layer version
+  list    : List layers
+  help    : Show this help
+
+Options:
+  -n, --name     : [all] Layer name
+      --arch     : [create] Compatible architecture [x86_64|arm64]
+      --artifact : [build] Output path of layer zip package
+`
+}
+
+// Run the command.
+func (l *Layer) Run(ctx *args.Context) error {
+	c := config.Load()
+	if !c.Exists() {
+		l.log.Error("Configuration file could not load. Run `ginger init` before.")
+		return errors.New("")
+	}
+	var err error
+	defer func() {
+		if err != nil {
+			l.log.Error(err.Error())
+			debugTrace(err)
+		}
+		c.Write()
+	}()
+
+	switch ctx.At(1) {
+	case LAYERCREATE:
+		err = l.createLayer(c, ctx)
+	case LAYERBUILD:
+		err = l.buildLayer(c, ctx)
+	case LAYERPUBLISH:
+		err = l.publishLayer(c, ctx)
+	case LAYERLIST:
+		err = l.listLayer(c, ctx)
+	default:
+		fmt.Println(l.Help())
+	}
+	return err
+}
+
+// createLayer creates new layer in local.
+//
+// >>> doc
+//
+// ## Create new layer
+//
+// Create new Lambda layer in `layers/[name]` directory.
+//
+// ```
+// $ ginger layer create [options]
+// ```
+//
+// | option  | description                                                                                   |
+// |:-------:|:----------------------------------------------------------------------------------------------|
+// | --name  | Layer name. If this option isn't supplied, ginger will ask it                                 |
+// | --arch  | Compatible architecture. enable values are `x86_64` or `arm64`. default is both architectures |
+//
+// Files in `layers/[name]/content` are put in the layer as it is, and they are extracted under `/opt` in Lambda environment.
+// Go binaries which are declared as `[[binaries]]` in `Layer.toml` are built for linux and put in `bin` directory.
+// Binaries are built for one architecture, so the layer must have exactly one `compatible_architectures`:
+//
+// ```
+// compatible_architectures = ["arm64"]
+//
+// [[binaries]]
+// name = "migrate"
+// main = "./cmd/migrate"
+// ```
+//
+// <<< doc
+func (l *Layer) createLayer(c *config.Config, ctx *args.Context) error {
+	name := ctx.String("name")
+	if name == "" {
+		name = input.String("Type layer name")
+	}
+	if name == "" {
+		return exception("Abort due to empty name.")
+	}
+	if _, err := c.LoadLayer(name); err == nil {
+		return exception("Layer %s already exists", name)
+	}
+
+	layer := &entity.Layer{
+		Name:               name,
+		CompatibleRuntimes: []string{entity.RuntimeProvidedAl2023, entity.RuntimeProvidedAl2},
+	}
+	if arch := ctx.String("arch"); arch != "" {
+		layer.CompatibleArchitectures = []string{arch}
+	}
+	if err := layer.Validate(); err != nil {
+		return exception("Invalid layer: %s", err.Error())
+	}
+	if err := os.MkdirAll(filepath.Join(c.LayerPath, name, config.LayerContentDir), 0755); err != nil {
+		return exception("Failed to create layer directory: %s", err.Error())
+	}
+	if err := c.WriteLayer(layer); err != nil {
+		return exception("Failed to write configuration: %s", err.Error())
+	}
+	l.log.Infof("Layer created. To manage its configuration, edit layers/%s/Layer.toml.\n", name)
+	return nil
+}
+
+// buildLayer builds layer package.
+//
+// >>> doc
+//
+// ## Build layer
+//
+// Build layer zip package which is the same as `ginger layer publish` uploads.
+//
+// ```
+// $ ginger layer build [options]
+// ```
+//
+// | option     | description                                                                 |
+// |:----------:|:----------------------------------------------------------------------------|
+// | --name     | Layer name. If this option isn't supplied, ginger will ask it by list UI    |
+// | --artifact | Output path of zip package. default is `.ginger/layers/[name].zip`          |
+//
+// <<< doc
+func (l *Layer) buildLayer(c *config.Config, ctx *args.Context) error {
+	layer, err := l.chooseLayer(c, ctx)
+	if err != nil {
+		return err
+	}
+	buffer, err := l.archive(c, layer)
+	if err != nil {
+		return err
+	}
+	artifact := ctx.String("artifact")
+	if artifact == "" {
+		artifact = filepath.Join(c.LibPath, "layers", layer.Name+".zip")
+		if err := os.MkdirAll(filepath.Dir(artifact), 0755); err != nil {
+			return exception("Failed to create directory: %s", err.Error())
+		}
+	}
+	if err := ioutil.WriteFile(artifact, buffer, 0644); err != nil {
+		return exception("Failed to write artifact: %s", err.Error())
+	}
+	sum := sha256.Sum256(buffer)
+	l.log.Infof("Artifact %s created successfully.\n", artifact)
+	l.log.Printf("SHA-256    : %s\n", hex.EncodeToString(sum[:]))
+	l.log.Printf("CodeSha256 : %s\n", base64.StdEncoding.EncodeToString(sum[:]))
+	return nil
+}
+
+// publishLayer builds and publishes layer as new layer version.
+//
+// >>> doc
+//
+// ## Publish layer
+//
+// Build layer and publish it as new layer version.
+//
+// ```
+// $ ginger layer publish [options]
+// ```
+//
+// | option  | description                                                               |
+// |:-------:|:--------------------------------------------------------------------------|
+// | --name  | Layer name. If this option isn't supplied, ginger will ask it by list UI  |
+//
+// If package is identical to the latest published version, publishing is skipped.
+// Published version is recorded in `Layer.toml`, and functions which declare `layers = ["[name]"]` in `Function.toml`
+// attach the latest version on next deployment.
+//
+// <<< doc
+func (l *Layer) publishLayer(c *config.Config, ctx *args.Context) error {
+	layer, err := l.chooseLayer(c, ctx)
+	if err != nil {
+		return err
+	}
+	buffer, err := l.archive(c, layer)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(buffer)
+	codeSha256 := base64.StdEncoding.EncodeToString(sum[:])
+	if layer.Arn != "" && layer.CodeSha256 == codeSha256 {
+		l.log.Printf("Layer %s is not changed, skip to publish. Latest version is %d\n", layer.Name, layer.Version)
+		return nil
+	}
+
+	lambda := request.NewLambda(c)
+	l.log.Printf("Publishing layer %s...\n", layer.Name)
+	result, err := lambda.PublishLayerVersion(layer, buffer)
+	if err != nil {
+		return exception("Failed to publish layer %s: %s", layer.Name, err.Error())
+	}
+	layer.Arn = aws.StringValue(result.LayerVersionArn)
+	layer.Version = aws.Int64Value(result.Version)
+	layer.CodeSha256 = codeSha256
+	if err := c.WriteLayer(layer); err != nil {
+		return exception("Failed to write configuration: %s", err.Error())
+	}
+	l.log.Infof("Layer %s version %d has been published.\n", layer.Name, layer.Version)
+	return nil
+}
+
+// listLayer shows registered layers.
+//
+// >>> doc
+//
+// ## List layers
+//
+// List registered layers with their latest published version.
+//
+// ```
+// $ ginger layer list
+// ```
+//
+// <<< doc
+func (l *Layer) listLayer(c *config.Config, ctx *args.Context) error {
+	layers, _ := c.LoadAllLayers()
+	t, err := tty.Open()
+	if err != nil {
+		return exception("Couldn't open tty")
+	}
+	defer t.Close()
+	w, _, err := t.Size()
+	if err != nil {
+		return exception("Couldn't get tty size")
+	}
+	line := strings.Repeat("=", w)
+	fmt.Println(line)
+	fmt.Printf("%-20s %-8s %-16s %-32s %-24s\n", "LayerName", "Version", "Architectures", "Runtimes", "Binaries")
+	fmt.Println(line)
+	for i, layer := range layers {
+		version := "-"
+		if layer.Version > 0 {
+			version = fmt.Sprint(layer.Version)
+		}
+		arch := "any"
+		if len(layer.CompatibleArchitectures) > 0 {
+			arch = strings.Join(layer.CompatibleArchitectures, ",")
+		}
+		binaries := []string{}
+		for _, b := range layer.Binaries {
+			binaries = append(binaries, b.Name)
+		}
+		fmt.Printf(
+			"%-20s %-8s %-16s %-32s %-24s\n",
+			layer.Name,
+			version,
+			arch,
+			strings.Join(layer.CompatibleRuntimes, ","),
+			strings.Join(binaries, ","),
+		)
+		if i != len(layers)-1 {
+			fmt.Println(strings.Repeat("-", w))
+		}
+	}
+	return nil
+}
+
+// chooseLayer loads layer from --name option or list UI, and validates it.
+func (l *Layer) chooseLayer(c *config.Config, ctx *args.Context) (*entity.Layer, error) {
+	name := ctx.String("name")
+	if name == "" {
+		name = c.ChooseLayer()
+	}
+	if name == "" {
+		return nil, exception("Layer name didn't supplied. Run with --name option.")
+	}
+	layer, err := c.LoadLayer(name)
+	if err != nil {
+		return nil, exception("Layer %s couldn't find in your project.", name)
+	}
+	if err := layer.Validate(); err != nil {
+		return nil, exception("Invalid layer \"%s\": %s", name, err.Error())
+	}
+	return layer, nil
+}
+
+// archive builds Go binaries of the layer and archives them with content files to zip.
+// Content files are put as relative path from content directory, and binaries are put in bin directory,
+// so they are extracted as /opt/... and /opt/bin/... in Lambda environment.
+// Entries have fixed modification time and permission as same as function package.
+func (l *Layer) archive(c *config.Config, layer *entity.Layer) ([]byte, error) {
+	src := filepath.Join(c.LayerPath, layer.Name)
+	tmpDir, err := ioutil.TempDir("", "ginger-layer")
+	if err != nil {
+		return nil, exception("Failed to create temporary directory: %s", err.Error())
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := map[string]string{}
+	content := filepath.Join(src, config.LayerContentDir)
+	if _, err := os.Stat(content); err == nil {
+		err := filepath.Walk(content, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(content, path)
+			if err != nil {
+				return err
+			}
+			files[filepath.ToSlash(rel)] = path
+			return nil
+		})
+		if err != nil {
+			return nil, exception("Failed to collect files: %s", err.Error())
+		}
+	}
+	for _, b := range layer.Binaries {
+		name := "bin/" + b.Name
+		if _, ok := files[name]; ok {
+			return nil, exception("Content file %s conflicts with binary", name)
+		}
+		out := filepath.Join(tmpDir, b.Name)
+		l.log.Printf("Building binary %s for %s...\n", b.Name, layer.GoArch())
+		if err := l.compileBinary(c, layer, b, out); err != nil {
+			return nil, exception("Failed to build %s binary: %s", b.Name, err.Error())
+		}
+		files[name] = out
+	}
+	if len(files) == 0 {
+		return nil, exception("Layer %s has no content. Put files in layers/%s/%s or declare binaries", layer.Name, layer.Name, config.LayerContentDir)
+	}
+
+	buf := new(bytes.Buffer)
+	z := zip.NewWriter(buf)
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		info, err := os.Stat(files[name])
+		if err != nil {
+			return nil, exception("File read error: %s", err.Error())
+		}
+		body, err := ioutil.ReadFile(files[name])
+		if err != nil {
+			return nil, exception("File read error: %s", err.Error())
+		}
+		var perm os.FileMode = 0644
+		if info.Mode()&0100 != 0 {
+			perm = 0755
+		}
+		if err := writeZipEntry(z, name, body, perm); err != nil {
+			return nil, err
+		}
+	}
+	if err := z.Close(); err != nil {
+		return nil, exception("Failed to close zip stream: %s", err.Error())
+	}
+	return buf.Bytes(), nil
+}
+
+// compileBinary builds Go binary of the layer as static linux binary.
+// Binary is built with reproducible flags and empty build id in order to make reproducible package.
+func (l *Layer) compileBinary(c *config.Config, layer *entity.Layer, b *entity.LayerBinary, out string) error {
+	buffer := new(bytes.Buffer)
+	dir := filepath.Join(c.LayerPath, layer.Name)
+	env := goSourceEnv(c, dir, "linux", layer.GoArch())
+	env["CGO_ENABLED"] = "0"
+	args := append([]string{"build", "-o", out}, reproducibleBuildFlags()...)
+	cmd := exec.Command("go", append(args, "-ldflags", "-buildid=", b.Main)...)
+	cmd.Dir = dir
+	cmd.Env = buildEnv(env)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = buffer
+	if err := cmd.Run(); err != nil {
+		return errors.New(buffer.String())
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+
+	"github.com/ysugimoto/ginger/config"
+	"github.com/ysugimoto/ginger/entity"
+	"github.com/ysugimoto/ginger/internal/openapi"
+	"github.com/ysugimoto/ginger/logger"
+	"github.com/ysugimoto/ginger/request"
+)
+
+// Security scheme name for API key required methods
+const apiKeySecurityScheme = "api_key"
+
+// openAPIBuilder is the struct which builds OpenAPI 3 document from project configuration.
+type openAPIBuilder struct {
+	config  *config.Config
+	log     *logger.Logger
+	account string
+}
+
+func newOpenAPIBuilder(c *config.Config, log *logger.Logger) *openAPIBuilder {
+	return &openAPIBuilder{
+		config: c,
+		log:    log,
+	}
+}
+
+// build walks resources and their integrations, and makes OpenAPI document.
+func (b *openAPIBuilder) build() (*openapi.Document, error) {
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: &openapi.Info{
+			Title:   b.config.ProjectName,
+			Version: "1.0.0",
+		},
+		Servers: b.servers(),
+		Paths:   make(map[string]openapi.PathItem),
+	}
+	schemes := map[string]*openapi.SecurityScheme{}
+
+	// Function name is used as operation id if the function is mounted only once
+	mounts := map[string]int{}
+	for _, r := range b.config.Resources {
+		for _, ig := range r.GetIntegrations() {
+			if ig.IntegrationType == "lambda" {
+				mounts[*ig.LambdaFunction]++
+			}
+		}
+	}
+
+	for _, r := range b.config.Resources {
+		igs := r.GetIntegrations()
+		if len(igs) == 0 {
+			continue
+		}
+		item := openapi.PathItem{}
+		for method, ig := range igs {
+			op, err := b.operation(r, method, ig)
+			if err != nil {
+				return nil, err
+			}
+			if ig.IntegrationType == "lambda" && mounts[*ig.LambdaFunction] == 1 {
+				op.OperationId = *ig.LambdaFunction
+			}
+			if ig.Authorizer != nil && *ig.Authorizer != "" {
+				au, err := b.config.LoadAuthorizer(*ig.Authorizer)
+				if err != nil {
+					return nil, exception("Authorizer %s couldn't find in your project.", *ig.Authorizer)
+				}
+				schemes[au.Name] = authorizerSecurityScheme(au)
+				op.Security = append(op.Security, map[string][]string{au.Name: []string{}})
+			}
+			if ig.ApiKeyRequired {
+				schemes[apiKeySecurityScheme] = &openapi.SecurityScheme{
+					Type: "apiKey",
+					Name: "x-api-key",
+					In:   "header",
+				}
+				op.Security = append(op.Security, map[string][]string{apiKeySecurityScheme: []string{}})
+			}
+			item[openapi.MethodKey(method)] = op
+		}
+		doc.Paths[r.Path] = item
+	}
+	if len(schemes) > 0 {
+		doc.Components = &openapi.Components{
+			SecuritySchemes: schemes,
+		}
+	}
+	return doc, nil
+}
+
+// servers makes server list from deployed stages.
+// Local stage files which haven't been deployed yet are not listed because they aren't reachable.
+func (b *openAPIBuilder) servers() []*openapi.Server {
+	if b.config.RestApiId == "" {
+		return nil
+	}
+	stages, err := request.NewAPIGateway(b.config).ListStages(b.config.RestApiId)
+	if err != nil {
+		b.log.Warn("Failed to get deployed stages. Servers are not exported.")
+		return nil
+	} else if len(stages) == 0 {
+		return nil
+	}
+	names := []string{}
+	for _, stg := range stages {
+		names = append(names, *stg.StageName)
+	}
+	sort.Strings(names)
+	return []*openapi.Server{
+		&openapi.Server{
+			Url: fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com/{basePath}", b.config.RestApiId, b.config.Region),
+			Variables: map[string]*openapi.ServerVariable{
+				"basePath": &openapi.ServerVariable{
+					Default: names[0],
+					Enum:    names,
+				},
+			},
+		},
+	}
+}
+
+// operation makes operation for the integration.
+func (b *openAPIBuilder) operation(r *entity.Resource, method string, ig *entity.Integration) (*openapi.Operation, error) {
+	op := &openapi.Operation{
+		OperationId: operationId(method, r.Path),
+		Responses: map[string]*openapi.Response{
+			"200": &openapi.Response{
+				Description: "200 response",
+			},
+		},
+	}
+	for _, name := range openapi.PathParameters(r.Path) {
+		op.Parameters = append(op.Parameters, &openapi.Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &openapi.Schema{Type: "string"},
+		})
+	}
+
+	switch ig.IntegrationType {
+	case "lambda":
+		uri, err := b.lambdaUri(*ig.LambdaFunction)
+		if err != nil {
+			return nil, err
+		}
+		op.Summary = *ig.LambdaFunction
+		op.Integration = &openapi.Integration{
+			Type:            "aws_proxy",
+			HttpMethod:      "POST",
+			Uri:             uri,
+			ContentHandling: "CONVERT_TO_BINARY",
+		}
+	case "s3":
+		op.Responses["200"].Headers = map[string]*openapi.Header{
+			"Content-Type": &openapi.Header{
+				Schema: &openapi.Schema{Type: "string"},
+			},
+		}
+		op.Integration = &openapi.Integration{
+			Type:            "http",
+			HttpMethod:      "ANY",
+			Uri:             fmt.Sprintf("https://s3.amazonaws.com/%s/{proxy}", strings.Trim(*ig.BucketPath, "/")),
+			ContentHandling: "CONVERT_TO_BINARY",
+			RequestParameters: map[string]string{
+				"integration.request.path.proxy": "method.request.path.proxy",
+			},
+			CacheKeyParameters: []string{"method.request.path.proxy"},
+			Responses: map[string]*openapi.IntegrationResponse{
+				"default": &openapi.IntegrationResponse{
+					StatusCode: "200",
+					ResponseParameters: map[string]string{
+						"method.response.header.Content-Type": "integration.response.header.Content-Type",
+					},
+					ContentHandling: "CONVERT_TO_BINARY",
+				},
+			},
+		}
+	default:
+		return nil, exception("Unexpected integration type %s for %s %s", ig.IntegrationType, method, r.Path)
+	}
+	return op, nil
+}
+
+// lambdaUri makes integration uri of function.
+// If function hasn't been deployed, the ARN is made from caller account.
+// Function reference can be qualified by alias like "name:alias".
+func (b *openAPIBuilder) lambdaUri(ref string) (string, error) {
+	name, qualifier := entity.SplitQualifier(ref)
+	fn, err := b.config.LoadFunction(name)
+	if err != nil {
+		return "", exception("Function %s couldn't find in your project.", name)
+	}
+	arn := fn.Arn
+	if arn == "" {
+		if b.account == "" {
+			if b.account, err = request.NewSts(b.config).GetAccount(); err != nil {
+				b.log.Warn("Failed to get AWS account. Use placeholder for function ARN.")
+				b.account = "{account}"
+			}
+		}
+		arn = fmt.Sprintf("arn:aws:lambda:%s:%s:function:%s", b.config.Region, b.account, name)
+	}
+	if qualifier != "" {
+		arn += ":" + qualifier
+	}
+	return fmt.Sprintf(
+		"arn:aws:apigateway:%s:lambda:path/2015-03-31/functions/%s/invocations",
+		b.config.Region,
+		arn,
+	), nil
+}
+
+// authorizerSecurityScheme makes security scheme which describes authorizer.
+func authorizerSecurityScheme(au *entity.Authorizer) *openapi.SecurityScheme {
+	header := "Authorization"
+	if strings.HasPrefix(au.IdentitySource, "method.request.header.") {
+		header = strings.TrimPrefix(strings.Split(au.IdentitySource, ",")[0], "method.request.header.")
+	}
+	authType := "custom"
+	if au.Type == entity.AuthorizerCognito {
+		authType = "cognito_user_pools"
+	}
+	return &openapi.SecurityScheme{
+		Type:     "apiKey",
+		Name:     header,
+		In:       "header",
+		AuthType: authType,
+	}
+}
+
+// operationId makes unique operation id from method and path like "getUsersId".
+func operationId(method, path string) string {
+	words := []string{strings.ToLower(method)}
+	for _, seg := range strings.Split(path, "/") {
+		if seg = strings.Trim(seg, "{}+"); seg != "" {
+			words = append(words, seg)
+		}
+	}
+	return strcase.ToLowerCamel(strings.Join(words, "_"))
+}
@@ -0,0 +1,27 @@
+package command
+
+import (
+	"testing"
+)
+
+func TestOperationId(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		expect string
+	}{
+		{method: "GET", path: "/", expect: "get"},
+		{method: "GET", path: "/users", expect: "getUsers"},
+		{method: "POST", path: "/users/{id}", expect: "postUsersId"},
+		{method: "ANY", path: "/static/{proxy+}", expect: "anyStaticProxy"},
+		{method: "DELETE", path: "/user_groups/{groupId}", expect: "deleteUserGroupsGroupId"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			if actual := operationId(tt.method, tt.path); actual != tt.expect {
+				t.Errorf("expected %s, got %s", tt.expect, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,524 @@
+package command
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/ysugimoto/go-args"
+
+	"github.com/ysugimoto/ginger/config"
+	"github.com/ysugimoto/ginger/entity"
+	"github.com/ysugimoto/ginger/internal/colors"
+	"github.com/ysugimoto/ginger/request"
+)
+
+// Plan change actions.
+// Remote only means the resource exists only on AWS, and deploy leaves it as it is.
+const (
+	planCreate     = "create"
+	planUpdate     = "update"
+	planRemoteOnly = "remote-only"
+)
+
+// planChangesDetected is returned from plan when changes are detected,
+// and exits with the distinct status from errors like terraform's -detailed-exitcode.
+const planChangesDetected = exitStatus(2)
+
+// planChange is the struct which represents a difference between local and AWS.
+type planChange struct {
+	action  string
+	kind    string
+	name    string
+	details []string
+}
+
+func newPlanChange(action, kind, name string) *planChange {
+	return &planChange{
+		action:  action,
+		kind:    kind,
+		name:    name,
+		details: make([]string, 0),
+	}
+}
+
+// addDetail appends field difference as "field: before -> after".
+func (p *planChange) addDetail(field string, before, after interface{}) {
+	p.details = append(p.details, fmt.Sprintf("%s: %v -> %v", field, before, after))
+}
+
+func (p *planChange) String() string {
+	var line string
+	switch p.action {
+	case planCreate:
+		line = colors.Green(fmt.Sprintf("+ %s %s", p.kind, p.name))
+	case planUpdate:
+		line = colors.Yellow(fmt.Sprintf("~ %s %s", p.kind, p.name))
+	case planRemoteOnly:
+		line = colors.Cyan(fmt.Sprintf("? %s %s (remote only)", p.kind, p.name))
+	}
+	for _, d := range p.details {
+		line += "\n    " + d
+	}
+	return line
+}
+
+// planDeployment shows differences between local configuration and AWS without any mutation.
+//
+// >>> doc
+//
+// ## Plan deployment
+//
+// Show create/update differences between local configuration and AWS.
+// This command only reads AWS resources, never mutates them.
+// Resources which exist only on AWS are shown as "remote only", because deploy doesn't delete them.
+//
+// ```
+// $ ginger deploy plan
+// ```
+//
+// The command exits with status 2 when some changes are detected, 0 when no changes, so you can use it on CI.
+// Remote only resources are not counted as changes. If AWS returns error other than not found, the command fails with status 1.
+//
+// <<< doc
+func (d *Deploy) planDeployment(c *config.Config, ctx *args.Context) error {
+	d.log.AddNamespace("plan")
+	defer d.log.RemoveNamespace("plan")
+
+	changes := []*planChange{}
+	planners := []func(*config.Config) ([]*planChange, error){
+		d.planFunctions,
+		d.planStorage,
+		d.planSchedulers,
+		d.planResources,
+		d.planStages,
+	}
+	for _, planner := range planners {
+		cs, err := planner(c)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, cs...)
+	}
+
+	count := 0
+	for _, change := range changes {
+		if change.action != planRemoteOnly {
+			count++
+		}
+	}
+	if len(changes) > 0 {
+		fmt.Println("")
+		for _, change := range changes {
+			fmt.Println(change.String())
+		}
+		fmt.Println("")
+	}
+	if count == 0 {
+		d.log.Info("No changes. Local configuration and AWS are up-to-date.")
+		return nil
+	}
+	d.log.Infof("%d change(s) detected.\n", count)
+	return planChangesDetected
+}
+
+// planFunctions compares Function.toml with Lambda function configurations.
+func (d *Deploy) planFunctions(c *config.Config) ([]*planChange, error) {
+	changes := []*planChange{}
+	functions, err := c.LoadAllFunctions()
+	if err != nil {
+		return nil, exception("Failed to list functions: %s", err.Error())
+	}
+	lambda := request.NewLambda(c)
+	for _, fn := range functions {
+		remote, err := lambda.GetFunction(fn.Name)
+		if err != nil {
+			if !request.IsNotFound(err) {
+				return nil, exception("Failed to get function %s: %s", fn.Name, err.Error())
+			}
+			changes = append(changes, newPlanChange(planCreate, "function", fn.Name))
+			continue
+		}
+		change := newPlanChange(planUpdate, "function", fn.Name)
+		if v := aws.Int64Value(remote.MemorySize); v != fn.MemorySize {
+			change.addDetail("memory_size", v, fn.MemorySize)
+		}
+		if v := aws.Int64Value(remote.Timeout); v != fn.Timeout {
+			change.addDetail("timeout", v, fn.Timeout)
+		}
+		role := fn.Role
+		if role == "" {
+			role = c.DefaultLambdaRole
+		}
+		if v := aws.StringValue(remote.Role); v != role {
+			change.addDetail("role", v, role)
+		}
+
+		remoteEnv := map[string]*string{}
+		if remote.Environment != nil && remote.Environment.Variables != nil {
+			remoteEnv = remote.Environment.Variables
+		}
+		for _, key := range mergeKeys(fn.Environment, remoteEnv) {
+			lv, lok := fn.Environment[key]
+			rv, rok := remoteEnv[key]
+			switch {
+			case lok && !rok:
+				change.addDetail("environment."+key, "(none)", aws.StringValue(lv))
+			case !lok && rok:
+				change.addDetail("environment."+key, aws.StringValue(rv), "(none)")
+			case aws.StringValue(lv) != aws.StringValue(rv):
+				change.addDetail("environment."+key, aws.StringValue(rv), aws.StringValue(lv))
+			}
+		}
+
+		var remoteSubnets, remoteGroups, localSubnets, localGroups []string
+		if remote.VpcConfig != nil {
+			remoteSubnets = aws.StringValueSlice(remote.VpcConfig.SubnetIds)
+			remoteGroups = aws.StringValueSlice(remote.VpcConfig.SecurityGroupIds)
+		}
+		if fn.VPC != nil {
+			localSubnets = fn.VPC.Subnets
+			localGroups = fn.VPC.SecurityGroups
+		}
+		if !sameStrings(remoteSubnets, localSubnets) {
+			change.addDetail("vpc.subnets", formatStrings(remoteSubnets), formatStrings(localSubnets))
+		}
+		if !sameStrings(remoteGroups, localGroups) {
+			change.addDetail("vpc.security_groups", formatStrings(remoteGroups), formatStrings(localGroups))
+		}
+
+		if len(change.details) > 0 {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// planResources compares resources and integrations with API Gateway.
+func (d *Deploy) planResources(c *config.Config) ([]*planChange, error) {
+	changes := []*planChange{}
+	if c.RestApiId == "" {
+		if len(c.Resources) > 0 {
+			changes = append(changes, newPlanChange(planCreate, "rest_api", fmt.Sprintf("ginger-%s", c.ProjectName)))
+		}
+		for _, r := range c.Resources {
+			changes = append(changes, d.planNewResource(r, c.ResolveCors(r)))
+		}
+		return changes, nil
+	}
+
+	api := request.NewAPIGateway(c)
+	items, err := api.GetResources(c.RestApiId)
+	if err != nil {
+		return nil, exception("Failed to get resources of REST API %s: %s", c.RestApiId, err.Error())
+	}
+	remotes := map[string]int{}
+	for i, item := range items {
+		remotes[aws.StringValue(item.Path)] = i
+	}
+
+	locals := map[string]struct{}{}
+	for _, r := range c.Resources {
+		locals[r.Path] = struct{}{}
+		index, ok := remotes[r.Path]
+		if !ok {
+			changes = append(changes, d.planNewResource(r, c.ResolveCors(r)))
+			continue
+		}
+		item := items[index]
+		change := newPlanChange(planUpdate, "resource", r.Path)
+		igs := r.GetIntegrations()
+		for method, ig := range igs {
+			m, ok := item.ResourceMethods[method]
+			if !ok || m.MethodIntegration == nil {
+				change.addDetail("integration."+method, "(none)", describeLocalIntegration(ig))
+				continue
+			}
+			remote := describeRemoteIntegration(m.MethodIntegration.Type, m.MethodIntegration.Uri)
+			if local := describeLocalIntegration(ig); remote != local {
+				change.addDetail("integration."+method, remote, local)
+			}
+		}
+		cors := c.ResolveCors(r)
+		if _, ok := item.ResourceMethods["OPTIONS"]; cors != nil && !ok && len(igs) > 0 {
+			change.addDetail("cors", "(none)", cors.String())
+		}
+		for method, m := range item.ResourceMethods {
+			if _, ok := igs[method]; ok {
+				continue
+			} else if method == "OPTIONS" && cors != nil {
+				continue
+			}
+			var remote string
+			if m.MethodIntegration != nil {
+				remote = describeRemoteIntegration(m.MethodIntegration.Type, m.MethodIntegration.Uri)
+			}
+			change.addDetail("integration."+method, remote, "(none)")
+		}
+		if len(change.details) > 0 {
+			changes = append(changes, change)
+		}
+	}
+
+	for path := range remotes {
+		if _, ok := locals[path]; ok || path == "/" {
+			continue
+		}
+		changes = append(changes, newPlanChange(planRemoteOnly, "resource", path))
+	}
+	return changes, nil
+}
+
+// planNewResource makes create change for the resource which doesn't exist on AWS.
+func (d *Deploy) planNewResource(r *entity.Resource, cors *entity.Cors) *planChange {
+	change := newPlanChange(planCreate, "resource", r.Path)
+	for method, ig := range r.GetIntegrations() {
+		change.addDetail("integration."+method, "(none)", describeLocalIntegration(ig))
+	}
+	if cors != nil && len(r.GetIntegrations()) > 0 {
+		change.addDetail("cors", "(none)", cors.String())
+	}
+	return change
+}
+
+// describeLocalIntegration formats local integration to compare with remote one.
+func describeLocalIntegration(ig *entity.Integration) string {
+	if ig.IntegrationType == "s3" && ig.BucketPath != nil {
+		return "s3:" + strings.Trim(*ig.BucketPath, "/")
+	}
+	return ig.String()
+}
+
+// describeRemoteIntegration formats remote integration as the same format of entity.Integration.String().
+func describeRemoteIntegration(iType, uri *string) string {
+	u := aws.StringValue(uri)
+	switch aws.StringValue(iType) {
+	case "AWS_PROXY":
+		// arn:aws:apigateway:{region}:lambda:path/2015-03-31/functions/{function arn}/invocations
+		u = strings.TrimSuffix(u, "/invocations")
+		if index := strings.LastIndex(u, ":function:"); index != -1 {
+			return "lambda:" + u[index+len(":function:"):]
+		}
+	case "HTTP":
+		// https://s3.amazonaws.com/{bucket path}/{proxy}
+		if strings.HasPrefix(u, "https://s3.amazonaws.com/") {
+			u = strings.TrimPrefix(u, "https://s3.amazonaws.com/")
+			return "s3:" + strings.TrimSuffix(u, "/{proxy}")
+		}
+	}
+	return strings.ToLower(aws.StringValue(iType)) + ":" + u
+}
+
+// planSchedulers compares schedulers with CloudWatch Events rules and targets.
+func (d *Deploy) planSchedulers(c *config.Config) ([]*planChange, error) {
+	changes := []*planChange{}
+	scs, err := c.LoadAllSchedulers()
+	if err != nil {
+		return nil, exception("Failed to get all schedulers: %s", err.Error())
+	}
+	cw := request.NewCloudWatch(c)
+	for _, sc := range scs {
+		state := "DISABLED"
+		if sc.Enable {
+			state = "ENABLED"
+		}
+		rule, err := cw.DescribeSchedule(sc.Name)
+		if err != nil {
+			if !request.IsNotFound(err) {
+				return nil, exception("Failed to describe scheduler %s: %s", sc.Name, err.Error())
+			}
+			change := newPlanChange(planCreate, "scheduler", sc.Name)
+			change.addDetail("expression", "(none)", sc.Expression)
+			change.addDetail("state", "(none)", state)
+			for _, name := range sc.Functions {
+				change.addDetail("target", "(none)", name)
+			}
+			changes = append(changes, change)
+			continue
+		}
+		change := newPlanChange(planUpdate, "scheduler", sc.Name)
+		if v := aws.StringValue(rule.ScheduleExpression); v != sc.Expression {
+			change.addDetail("expression", v, sc.Expression)
+		}
+		if v := aws.StringValue(rule.State); v != state {
+			change.addDetail("state", v, state)
+		}
+		targets, err := cw.ListTargets(sc.Name)
+		if err != nil {
+			return nil, exception("Failed to list targets for %s: %s", sc.Name, err.Error())
+		}
+		remoteFunctions := []string{}
+		for _, t := range targets {
+			arn := aws.StringValue(t.Arn)
+			if index := strings.LastIndex(arn, ":function:"); index != -1 {
+				arn = arn[index+len(":function:"):]
+			}
+			remoteFunctions = append(remoteFunctions, arn)
+		}
+		if !sameStrings(remoteFunctions, sc.Functions) {
+			change.addDetail("targets", formatStrings(remoteFunctions), formatStrings(sc.Functions))
+		}
+		if len(change.details) > 0 {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// planStorage compares storage files with S3 objects.
+func (d *Deploy) planStorage(c *config.Config) ([]*planChange, error) {
+	changes := []*planChange{}
+	locals, err := d.listLocalObjects(c.StoragePath)
+	if err != nil {
+		return nil, exception("Failed to list local storage files: %s", err.Error())
+	} else if len(locals) == 0 {
+		return changes, nil
+	}
+
+	s3 := request.NewS3(c)
+	remotes := map[string]string{}
+	exists, err := s3.BucketExists(c.S3BucketName)
+	if err != nil {
+		return nil, exception("Failed to check bucket %s: %s", c.S3BucketName, err.Error())
+	} else if exists {
+		objects, err := s3.ListObjects(c.S3BucketName)
+		if err != nil {
+			return nil, exception("Failed to list objects in %s: %s", c.S3BucketName, err.Error())
+		}
+		for _, o := range objects {
+			// Function and layer packages are not storage files
+			if config.IsArtifactKey(aws.StringValue(o.Key)) {
+				continue
+			}
+			remotes[aws.StringValue(o.Key)] = strings.Trim(aws.StringValue(o.ETag), `"`)
+		}
+	} else {
+		changes = append(changes, newPlanChange(planCreate, "bucket", c.S3BucketName))
+	}
+
+	for _, so := range locals {
+		key := so.Key
+		etag, ok := remotes[key]
+		if !ok {
+			changes = append(changes, newPlanChange(planCreate, "object", key))
+			continue
+		}
+		delete(remotes, key)
+		sum := md5.Sum(so.Data)
+		if local := hex.EncodeToString(sum[:]); local != etag {
+			change := newPlanChange(planUpdate, "object", key)
+			change.addDetail("etag", etag, local)
+			changes = append(changes, change)
+		}
+	}
+	for key := range remotes {
+		changes = append(changes, newPlanChange(planRemoteOnly, "object", key))
+	}
+	return changes, nil
+}
+
+// planStages compares stage variables with API Gateway stages.
+func (d *Deploy) planStages(c *config.Config) ([]*planChange, error) {
+	changes := []*planChange{}
+	stages, err := c.LoadAllStages()
+	if err != nil {
+		return nil, exception("Failed to list stages: %s", err.Error())
+	}
+	remotes := map[string]map[string]*string{}
+	if c.RestApiId != "" {
+		items, err := request.NewAPIGateway(c).ListStages(c.RestApiId)
+		if err != nil {
+			return nil, exception("Failed to get stages of REST API %s: %s", c.RestApiId, err.Error())
+		}
+		for _, s := range items {
+			remotes[aws.StringValue(s.StageName)] = s.Variables
+		}
+	}
+
+	for _, stg := range stages {
+		variables, ok := remotes[stg.Name]
+		if !ok {
+			change := newPlanChange(planCreate, "stage", stg.Name)
+			for _, key := range mergeKeys(stg.Variables, nil) {
+				change.addDetail("variables."+key, "(none)", stg.Variables[key])
+			}
+			changes = append(changes, change)
+			continue
+		}
+		delete(remotes, stg.Name)
+		change := newPlanChange(planUpdate, "stage", stg.Name)
+		for _, key := range mergeKeys(stg.Variables, variables) {
+			lv, lok := stg.Variables[key]
+			rv, rok := variables[key]
+			switch {
+			case lok && !rok:
+				change.addDetail("variables."+key, "(none)", lv)
+			case !lok && rok:
+				change.addDetail("variables."+key, aws.StringValue(rv), "(none)")
+			case lv != aws.StringValue(rv):
+				change.addDetail("variables."+key, aws.StringValue(rv), lv)
+			}
+		}
+		if len(change.details) > 0 {
+			changes = append(changes, change)
+		}
+	}
+	for name := range remotes {
+		changes = append(changes, newPlanChange(planRemoteOnly, "stage", name))
+	}
+	return changes, nil
+}
+
+// mergeKeys returns sorted keys which are contained in either maps.
+// Accept map[string]string or map[string]*string.
+func mergeKeys(maps ...interface{}) []string {
+	keys := []string{}
+	exists := map[string]struct{}{}
+	add := func(key string) {
+		if _, ok := exists[key]; !ok {
+			exists[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	for _, m := range maps {
+		switch v := m.(type) {
+		case map[string]string:
+			for key := range v {
+				add(key)
+			}
+		case map[string]*string:
+			for key := range v {
+				add(key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sameStrings compares string slices without order.
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x := append([]string{}, a...)
+	y := append([]string{}, b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func formatStrings(values []string) string {
+	if len(values) == 0 {
+		return "(none)"
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
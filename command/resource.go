@@ -3,11 +3,15 @@ package command
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"crypto/tls"
+	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
+	"net/url"
 
 	"github.com/mattn/go-tty"
 	"github.com/ysugimoto/go-args"
@@ -15,6 +19,7 @@ import (
 	"github.com/ysugimoto/ginger/config"
 	"github.com/ysugimoto/ginger/entity"
 	"github.com/ysugimoto/ginger/input"
+	"github.com/ysugimoto/ginger/internal/openapi"
 	"github.com/ysugimoto/ginger/logger"
 	"github.com/ysugimoto/ginger/request"
 )
@@ -25,6 +30,8 @@ const (
 	RESOURCEINVOKE = "invoke"
 	RESOURCEDEPLOY = "deploy"
 	RESOURCELIST   = "list"
+	RESOURCEEXPORT = "export"
+	RESOURCEIMPORT = "import"
 	RESOURCEHELP   = "help"
 )
 
@@ -56,6 +63,8 @@ Operation:
   invoke  : Invoke resources
   deploy  : Deploy resource
   list    : List resources
+  export  : Export resources as API document
+  import  : Import resources and functions from API document
   help    : Show this help
 
 Options:
@@ -63,6 +72,8 @@ Options:
   -s, --stage  : [invoke] Target stage
   -m, --method : [invoke] Method name (default=GET)
   -b, --body   : [invoke] Request payload (POST/PUT method only)
+      --format : [export] Document format (default=openapi)
+      --file   : [export,import] Output file path (default=stdout), or input file path
 `
 }
 
@@ -92,9 +103,14 @@ func (r *Resource) Run(ctx *args.Context) error {
 	case RESOURCEINVOKE:
 		err = r.invokeEndpoint(c, ctx)
 	case RESOURCEDEPLOY:
-		err = NewDeploy().deployResource(c, ctx)
+		d := NewDeploy()
+		err = d.report(d.deployResource(c, ctx))
 	case RESOURCELIST:
 		err = r.listEndpoint(c, ctx)
+	case RESOURCEEXPORT:
+		err = r.exportEndpoint(c, ctx)
+	case RESOURCEIMPORT:
+		err = r.importEndpoint(c, ctx)
 	default:
 		fmt.Println(r.Help())
 	}
@@ -246,3 +262,248 @@ func (r *Resource) listEndpoint(c *config.Config, ctx *args.Context) error {
 	}
 	return nil
 }
+
+// exportEndpoint exports resources and integrations as API document.
+//
+// >>> doc
+//
+// ## Export resources
+//
+// Export resources, integrations and mounted functions as OpenAPI 3 document.
+//
+// ```
+// $ ginger resource export [options]
+// ```
+//
+// | option   | description                                                   |
+// |:--------:|:--------------------------------------------------------------|
+// | --format | Document format. Currently only `openapi` is supported        |
+// | --file   | Output file path. If this option isn't supplied, print stdout |
+//
+// Path parameters are generated from `{param}` segments,
+// and lambda/s3 integrations are exported as `x-amazon-apigateway-integration` extension.
+// Deployed stages are exported as `servers`. When the document is printed to stdout, logs are written to stderr.
+//
+// <<< doc
+func (r *Resource) exportEndpoint(c *config.Config, ctx *args.Context) error {
+	if format := ctx.String("format"); format != "" && format != "openapi" {
+		return exception("Unsupported format %s. Only openapi is supported.", format)
+	}
+	file := ctx.String("file")
+	if file == "" {
+		// Document is written to stdout, so logs must not be mixed
+		logger.UseStderr()
+	}
+	doc, err := newOpenAPIBuilder(c, r.log).build()
+	if err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return exception("Failed to encode document: %s", err.Error())
+	}
+	if file == "" {
+		fmt.Println(string(buf))
+		return nil
+	}
+	if err := ioutil.WriteFile(file, append(buf, '\n'), 0644); err != nil {
+		return exception("Failed to write document: %s", err.Error())
+	}
+	r.log.Infof("OpenAPI document exported to %s successfully.\n", file)
+	return nil
+}
+
+// importEndpoint creates resources, functions and integrations from API document.
+//
+// >>> doc
+//
+// ## Import resources
+//
+// Import resources from OpenAPI 3 document, and scaffold functions for each operation.
+//
+// ```
+// $ ginger resource import [options]
+// ```
+//
+// | option    | description                                     |
+// |:---------:|:------------------------------------------------|
+// | --file    | [Required] OpenAPI document file (YAML or JSON) |
+// | --memory  | Memory size of created functions (default=128)  |
+// | --timeout | Timeout of created functions (default=3)        |
+//
+// Function is created as `functions/<operationId>` from API Gateway template, and mounted to the operation.
+// If `operationId` isn't defined, function name is made from method and path like `getUsersId`.
+// The function which already exists is mounted as it is, and the method which is already mounted is skipped.
+// If operation has `x-amazon-apigateway-integration`, lambda integration mounts the function in its uri
+// which must exist in your project, and S3 integration is mounted as storage. Other integrations are skipped.
+// If operation requires `api_key` security, `api_key_required` is set to the integration,
+// and security which has the same name of authorizer is set as integration authorizer.
+// Document level `security` is applied to operations which don't declare their own.
+//
+// <<< doc
+func (r *Resource) importEndpoint(c *config.Config, ctx *args.Context) error {
+	file := ctx.String("file")
+	if file == "" {
+		return exception("Document file is required. Run with --file option.")
+	}
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return exception("Failed to read document: %s", err.Error())
+	}
+	doc, err := openapi.Load(buf)
+	if err != nil {
+		return exception("Failed to load document: %s", err.Error())
+	}
+
+	paths := []string{}
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	f := NewFunction()
+	for _, path := range paths {
+		rs, err := c.LoadResource(path)
+		if err != nil {
+			rs = entity.NewResource("", path)
+			c.Resources = append(c.Resources, rs)
+			r.log.Infof("Resource \"%s\" created successfully.\n", rs.Path)
+		}
+		rs.UserDefined = true
+
+		keys := []string{}
+		for key := range doc.Paths[path] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			method := openapi.Method(key)
+			if rs.GetIntegration(method) != nil {
+				r.log.Warnf("%s %s has already been mounted. Skip it.\n", method, rs.Path)
+				continue
+			}
+			op := doc.Paths[path][key]
+			ig, err := r.importIntegration(c, ctx, f, method, rs.Path, op)
+			if err != nil {
+				return err
+			} else if ig == nil {
+				continue
+			}
+			for _, sec := range doc.OperationSecurity(op) {
+				for scheme := range sec {
+					if scheme == apiKeySecurityScheme {
+						ig.ApiKeyRequired = true
+					} else if au, err := c.LoadAuthorizer(scheme); err == nil {
+						ig.Authorizer = &au.Name
+					}
+				}
+			}
+			rs.AddIntegration(method, ig)
+			r.log.Infof("%s mouted to %s %s.\n", ig.String(), method, rs.Path)
+		}
+	}
+	return nil
+}
+
+// importIntegration makes integration from operation.
+// Lambda integration mounts the function in the integration uri, and operation without integration scaffolds new function.
+// S3 integration is mapped to storage integration, and other integrations are skipped because ginger can't manage them.
+func (r *Resource) importIntegration(
+	c *config.Config,
+	ctx *args.Context,
+	f *Function,
+	method, path string,
+	op *openapi.Operation,
+) (*entity.Integration, error) {
+	if op.Integration != nil {
+		if bucketPath, ok := s3BucketPath(op.Integration); ok {
+			return entity.NewIntegration("s3", bucketPath, path), nil
+		}
+		name, ok := lambdaFunctionRef(op.Integration)
+		if !ok {
+			r.log.Warnf("%s %s has unsupported %s integration. Skip it.\n", method, path, op.Integration.Type)
+			return nil, nil
+		}
+		fn, _ := entity.SplitQualifier(name)
+		if _, err := c.LoadFunction(fn); err != nil {
+			r.log.Warnf("Function %s couldn't find in your project. Skip %s %s.\n", fn, method, path)
+			return nil, nil
+		}
+		return entity.NewIntegration("lambda", name, path), nil
+	}
+
+	name := functionNameFromOperation(op.OperationId)
+	if name == "" {
+		name = operationId(method, path)
+	}
+	if _, err := c.LoadFunction(name); err != nil {
+		if _, err := f.scaffoldFunction(c, name, eventNameAPIGateway, int64(ctx.Int("memory")), int64(ctx.Int("timeout"))); err != nil {
+			return nil, err
+		}
+		r.log.Infof("Function \"%s\" created successfully.\n", name)
+	}
+	return entity.NewIntegration("lambda", name, path), nil
+}
+
+// s3BucketPath returns bucket path of S3 integration.
+// Both of HTTP integration to S3 endpoint which ginger exports, and AWS service integration are accepted.
+func s3BucketPath(ig *openapi.Integration) (string, bool) {
+	var p string
+	switch ig.Type {
+	case "http", "http_proxy":
+		u, err := url.Parse(ig.Uri)
+		// Path style endpoint like "s3.amazonaws.com" or "s3.ap-northeast-1.amazonaws.com"
+		if err != nil || !strings.HasPrefix(u.Host, "s3") || !strings.HasSuffix(u.Host, ".amazonaws.com") {
+			return "", false
+		}
+		p = u.Path
+	case "aws":
+		index := strings.Index(ig.Uri, ":s3:path/")
+		if index == -1 {
+			return "", false
+		}
+		p = ig.Uri[index+len(":s3:path/"):]
+	default:
+		return "", false
+	}
+	// Trailing path parameter segment like "{proxy}" is the object key
+	segments := []string{}
+	for _, seg := range strings.Split(strings.Trim(p, "/"), "/") {
+		if strings.HasPrefix(seg, "{") {
+			break
+		}
+		segments = append(segments, seg)
+	}
+	if len(segments) == 0 || segments[0] == "" {
+		return "", false
+	}
+	return strings.Join(segments, "/") + "/", true
+}
+
+// lambdaFunctionRef returns function reference like "name" or "name:alias" from lambda integration uri.
+func lambdaFunctionRef(ig *openapi.Integration) (string, bool) {
+	if ig.Type != "aws" && ig.Type != "aws_proxy" {
+		return "", false
+	}
+	index := strings.Index(ig.Uri, ":function:")
+	if index == -1 || !strings.Contains(ig.Uri, ":lambda:path/") {
+		return "", false
+	}
+	ref := strings.TrimSuffix(ig.Uri[index+len(":function:"):], "/invocations")
+	if ref == "" {
+		return "", false
+	}
+	return ref, true
+}
+
+// functionNameFromOperation makes function name from operation id.
+// Lambda function name accepts only alphanumerics, hyphens and underscores.
+func functionNameFromOperation(id string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return -1
+	}, id)
+}
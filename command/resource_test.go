@@ -0,0 +1,124 @@
+package command
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ysugimoto/ginger/internal/openapi"
+)
+
+func TestS3BucketPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		ig     *openapi.Integration
+		expect string
+		ok     bool
+	}{
+		{
+			name:   "exported storage integration",
+			ig:     &openapi.Integration{Type: "http", Uri: "https://s3.amazonaws.com/bucket/assets/{proxy}"},
+			expect: "bucket/assets/",
+			ok:     true,
+		},
+		{
+			name:   "bucket root",
+			ig:     &openapi.Integration{Type: "http", Uri: "https://s3.amazonaws.com/bucket/{proxy}"},
+			expect: "bucket/",
+			ok:     true,
+		},
+		{
+			name:   "regional endpoint",
+			ig:     &openapi.Integration{Type: "http_proxy", Uri: "https://s3.ap-northeast-1.amazonaws.com/bucket/{key}"},
+			expect: "bucket/",
+			ok:     true,
+		},
+		{
+			name:   "aws service integration",
+			ig:     &openapi.Integration{Type: "aws", Uri: "arn:aws:apigateway:us-east-1:s3:path/bucket/dir/{item}"},
+			expect: "bucket/dir/",
+			ok:     true,
+		},
+		{
+			name: "other http endpoint",
+			ig:   &openapi.Integration{Type: "http", Uri: "https://example.com/bucket/{proxy}"},
+		},
+		{
+			name: "lambda integration",
+			ig:   &openapi.Integration{Type: "aws_proxy", Uri: "arn:aws:apigateway:us-east-1:lambda:path/2015-03-31/functions/arn:aws:lambda:us-east-1:000000000000:function:foo/invocations"},
+		},
+		{
+			name: "no bucket",
+			ig:   &openapi.Integration{Type: "http", Uri: "https://s3.amazonaws.com/{proxy}"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok := s3BucketPath(tt.ig)
+			if ok != tt.ok || actual != tt.expect {
+				t.Errorf("expected (%q, %t), got (%q, %t)", tt.expect, tt.ok, actual, ok)
+			}
+		})
+	}
+}
+
+func TestLambdaFunctionRef(t *testing.T) {
+	uri := "arn:aws:apigateway:us-east-1:lambda:path/2015-03-31/functions/arn:aws:lambda:us-east-1:000000000000:function:%s/invocations"
+	tests := []struct {
+		name   string
+		ig     *openapi.Integration
+		expect string
+		ok     bool
+	}{
+		{
+			name:   "proxy integration",
+			ig:     &openapi.Integration{Type: "aws_proxy", Uri: fmt.Sprintf(uri, "foo")},
+			expect: "foo",
+			ok:     true,
+		},
+		{
+			name:   "qualified function",
+			ig:     &openapi.Integration{Type: "aws", Uri: fmt.Sprintf(uri, "foo:live")},
+			expect: "foo:live",
+			ok:     true,
+		},
+		{
+			name: "http integration",
+			ig:   &openapi.Integration{Type: "http", Uri: "https://example.com/"},
+		},
+		{
+			name: "other aws service",
+			ig:   &openapi.Integration{Type: "aws", Uri: "arn:aws:apigateway:us-east-1:sqs:path/000000000000/queue"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok := lambdaFunctionRef(tt.ig)
+			if ok != tt.ok || actual != tt.expect {
+				t.Errorf("expected (%q, %t), got (%q, %t)", tt.expect, tt.ok, actual, ok)
+			}
+		})
+	}
+}
+
+func TestFunctionNameFromOperation(t *testing.T) {
+	tests := []struct {
+		id     string
+		expect string
+	}{
+		{id: "getUsers", expect: "getUsers"},
+		{id: "get-users_v2", expect: "get-users_v2"},
+		{id: "users.list", expect: "userslist"},
+		{id: "get users/{id}", expect: "getusersid"},
+		{id: "", expect: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if actual := functionNameFromOperation(tt.id); actual != tt.expect {
+				t.Errorf("expected %s, got %s", tt.expect, actual)
+			}
+		})
+	}
+}
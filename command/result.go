@@ -0,0 +1,102 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ysugimoto/ginger/internal/colors"
+)
+
+// Deployment statuses
+const (
+	resultSuccess = "success"
+	resultFailed  = "failed"
+	resultSkipped = "skipped"
+)
+
+// deployEntry is the struct which records deployment status for each resource.
+type deployEntry struct {
+	kind    string
+	name    string
+	status  string
+	message string
+}
+
+// deployResult is the struct which collects deployment statuses of functions, resources, schedulers and so on.
+// Deploy command prints summary table from this result, and exits with non-zero status if some deployments failed.
+type deployResult struct {
+	entries []*deployEntry
+}
+
+func newDeployResult() *deployResult {
+	return &deployResult{
+		entries: make([]*deployEntry, 0),
+	}
+}
+
+func (r *deployResult) add(kind, name, status, message string) {
+	r.entries = append(r.entries, &deployEntry{
+		kind:    kind,
+		name:    name,
+		status:  status,
+		message: message,
+	})
+}
+
+// success records successful deployment.
+func (r *deployResult) success(kind, name string) {
+	r.add(kind, name, resultSuccess, "")
+}
+
+// fail records failed deployment with its reason.
+func (r *deployResult) fail(kind, name string, err error) {
+	message := ""
+	if err != nil {
+		message = strings.TrimSpace(err.Error())
+	}
+	r.add(kind, name, resultFailed, message)
+}
+
+// skip records skipped deployment with its reason.
+func (r *deployResult) skip(kind, name, reason string) {
+	r.add(kind, name, resultSkipped, reason)
+}
+
+// failures returns count of failed deployments.
+func (r *deployResult) failures() int {
+	count := 0
+	for _, e := range r.entries {
+		if e.status == resultFailed {
+			count++
+		}
+	}
+	return count
+}
+
+// print displays summary table of deployment.
+func (r *deployResult) print() {
+	if len(r.entries) == 0 {
+		return
+	}
+	line := strings.Repeat("=", 80)
+	fmt.Println(line)
+	fmt.Printf("%-12s %-36s %-8s %s\n", "Kind", "Name", "Status", "Message")
+	fmt.Println(line)
+	for _, e := range r.entries {
+		status := fmt.Sprintf("%-8s", e.status)
+		switch e.status {
+		case resultSuccess:
+			status = colors.Green(status)
+		case resultFailed:
+			status = colors.Red(status)
+		case resultSkipped:
+			status = colors.Yellow(status)
+		}
+		message := e.message
+		if index := strings.Index(message, "\n"); index != -1 {
+			message = message[0:index] + "..."
+		}
+		fmt.Printf("%-12s %-36s %s %s\n", e.kind, e.name, status, message)
+	}
+	fmt.Println(line)
+}
@@ -0,0 +1,293 @@
+package command
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"net/rpc"
+	"os/exec"
+
+	"github.com/aws/aws-lambda-go/lambda/messages"
+
+	"github.com/ysugimoto/ginger/entity"
+	"github.com/ysugimoto/ginger/logger"
+)
+
+// Timeout to wait until local lambda process becomes ready
+const localLambdaReadyTimeout = 10 * time.Second
+
+// Protocols which local lambda process speaks
+const (
+	protocolRPC        = "rpc"
+	protocolRuntimeAPI = "runtime-api"
+)
+
+// localLambda is the struct of running local Lambda process.
+// Each process listens on its own port, so many processes can run concurrently.
+type localLambda struct {
+	fn         *entity.Function
+	port       string
+	protocol   string
+	api        *runtimeAPI
+	cmd        *exec.Cmd
+	done       chan struct{}
+	generation int
+}
+
+// allocatePort finds free TCP port on loopback interface.
+func allocatePort() (string, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer ln.Close()
+	return fmt.Sprint(ln.Addr().(*net.TCPAddr).Port), nil
+}
+
+// Start built function binary as local Lambda process.
+// Process is started with both of _LAMBDA_SERVER_PORT (go1.x RPC) and AWS_LAMBDA_RUNTIME_API (custom runtime),
+// and function's lambda library chooses the protocol which it supports.
+// We run server by using built binary because `go run main.go` process cannot kill its process properly.
+// The `go run main.go` makes temporary binary and run at `/var/folders/xxxxx/exe/main`,
+// and if we kill process via cmd.Process.Kill(), then that process won't kill, so RPC process runs forever.
+func startLocalLambda(fn *entity.Function, bin string) (*localLambda, error) {
+	port, err := allocatePort()
+	if err != nil {
+		return nil, exception("Failed to allocate port for local lambda: %s", err.Error())
+	}
+	api, err := startRuntimeAPI(fn)
+	if err != nil {
+		return nil, exception("Failed to start local Runtime API: %s", err.Error())
+	}
+	cmd := exec.Command(bin)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = buildEnv(map[string]string{
+		"_LAMBDA_SERVER_PORT":             port,
+		"AWS_LAMBDA_RUNTIME_API":          api.address(),
+		"AWS_LAMBDA_FUNCTION_NAME":        fn.Name,
+		"AWS_LAMBDA_FUNCTION_VERSION":     "$LATEST",
+		"AWS_LAMBDA_FUNCTION_MEMORY_SIZE": fmt.Sprint(fn.MemorySize),
+	})
+	// Append function specific environments
+	for k, v := range fn.Environment {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, *v))
+	}
+	if err := cmd.Start(); err != nil {
+		api.close()
+		return nil, exception("Failed to start local lambda: %s", err.Error())
+	}
+	l := &localLambda{
+		fn:   fn,
+		port: port,
+		api:  api,
+		cmd:  cmd,
+		done: make(chan struct{}),
+	}
+	go func() {
+		cmd.Wait()
+		close(l.done)
+	}()
+	if err := l.waitReady(localLambdaReadyTimeout); err != nil {
+		l.stop()
+		return nil, err
+	}
+	return l, nil
+}
+
+// waitReady waits until process polls next invocation via Runtime API or responds to RPC ping,
+// and determine the protocol to invoke.
+func (l *localLambda) waitReady(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		if l.api.isReady() {
+			l.protocol = protocolRuntimeAPI
+			l.port = l.api.port()
+			return nil
+		} else if l.exited() {
+			if e := l.api.reportedInitError(); e != nil {
+				return exception("Local lambda %s failed to initialize: %s: %s", l.fn.Name, e.Type, e.Message)
+			}
+			return exception("Local lambda %s exited before ready", l.fn.Name)
+		}
+		if client, err := rpc.Dial("tcp", "127.0.0.1:"+l.port); err == nil {
+			err = client.Call("Function.Ping", &messages.PingRequest{}, &messages.PingResponse{})
+			client.Close()
+			if err == nil {
+				l.protocol = protocolRPC
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return exception("Local lambda %s didn't become ready in %s", l.fn.Name, timeout)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// exited returns true if process has already exited.
+func (l *localLambda) exited() bool {
+	select {
+	case <-l.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// invoke calls local lambda with payload.
+func (l *localLambda) invoke(source, clientContext []byte) (*messages.InvokeResponse, error) {
+	if l.protocol == protocolRuntimeAPI {
+		return l.api.invoke(source, clientContext, l.done)
+	}
+	return execLambdaRPC(l.port, l.fn.Timeout, source, clientContext)
+}
+
+// stop terminates process gracefully, and kill it if process doesn't exit in a few seconds.
+func (l *localLambda) stop() {
+	l.cmd.Process.Signal(syscall.SIGTERM)
+	select {
+	case <-l.done:
+	case <-time.After(3 * time.Second):
+		l.cmd.Process.Kill()
+		<-l.done
+	}
+	l.api.close()
+}
+
+// localRuntime is the struct which manages local lambda processes.
+// Process is reused across invocations like warm container of AWS Lambda,
+// and new process is started (cold start) when all processes of the function are busy.
+type localRuntime struct {
+	log         *logger.Logger
+	mu          sync.Mutex
+	idle        map[string][]*localLambda
+	processes   map[*localLambda]struct{}
+	generations map[string]int
+}
+
+func newLocalRuntime(log *logger.Logger) *localRuntime {
+	return &localRuntime{
+		log:         log,
+		idle:        make(map[string][]*localLambda),
+		processes:   make(map[*localLambda]struct{}),
+		generations: make(map[string]int),
+	}
+}
+
+// acquire takes idle process of the function, or starts new process.
+// Second return value is true if new process is started.
+func (r *localRuntime) acquire(fn *entity.Function, bin string) (*localLambda, bool, error) {
+	r.mu.Lock()
+	for len(r.idle[fn.Name]) > 0 {
+		ps := r.idle[fn.Name]
+		l := ps[len(ps)-1]
+		r.idle[fn.Name] = ps[:len(ps)-1]
+		if !l.exited() {
+			r.mu.Unlock()
+			return l, false, nil
+		}
+		delete(r.processes, l)
+	}
+	generation := r.generations[fn.Name]
+	r.mu.Unlock()
+
+	l, err := startLocalLambda(fn, bin)
+	if err != nil {
+		return nil, false, err
+	}
+	l.generation = generation
+	r.mu.Lock()
+	r.processes[l] = struct{}{}
+	r.mu.Unlock()
+	return l, true, nil
+}
+
+// release returns process to idle pool.
+// If function has been reloaded while invocation, the process is stopped because it runs old binary.
+func (r *localRuntime) release(l *localLambda) {
+	r.mu.Lock()
+	if l.exited() || l.generation != r.generations[l.fn.Name] {
+		delete(r.processes, l)
+		r.mu.Unlock()
+		l.stop()
+		return
+	}
+	r.idle[l.fn.Name] = append(r.idle[l.fn.Name], l)
+	r.mu.Unlock()
+}
+
+// discard stops process which is in broken state.
+func (r *localRuntime) discard(l *localLambda) {
+	r.mu.Lock()
+	delete(r.processes, l)
+	r.mu.Unlock()
+	l.stop()
+}
+
+// warm starts process of the function in advance if there is no idle process.
+func (r *localRuntime) warm(fn *entity.Function, bin string) error {
+	l, _, err := r.acquire(fn, bin)
+	if err != nil {
+		return err
+	}
+	r.release(l)
+	return nil
+}
+
+// invoke calls function with payload on idle or new process.
+func (r *localRuntime) invoke(fn *entity.Function, bin string, source, clientContext []byte) (*messages.InvokeResponse, error) {
+	start := time.Now()
+	l, cold, err := r.acquire(fn, bin)
+	if err != nil {
+		return nil, err
+	}
+	init := time.Since(start)
+	resp, err := l.invoke(source, clientContext)
+	if err != nil {
+		r.discard(l)
+		return nil, err
+	}
+	r.release(l)
+	if cold {
+		r.log.Printf("%s: cold start on port %s via %s (init %s, duration %s)\n", fn.Name, l.port, l.protocol, init, time.Since(start)-init)
+	} else {
+		r.log.Printf("%s: warm invocation on port %s (duration %s)\n", fn.Name, l.port, time.Since(start))
+	}
+	return resp, nil
+}
+
+// reload stops idle processes of the function, and busy processes are stopped after invocation.
+// Call this function when function binary is rebuilt.
+func (r *localRuntime) reload(name string) {
+	r.mu.Lock()
+	r.generations[name]++
+	ps := r.idle[name]
+	delete(r.idle, name)
+	for _, l := range ps {
+		delete(r.processes, l)
+	}
+	r.mu.Unlock()
+	for _, l := range ps {
+		l.stop()
+	}
+}
+
+// shutdown stops all processes.
+func (r *localRuntime) shutdown() {
+	r.mu.Lock()
+	ps := []*localLambda{}
+	for l := range r.processes {
+		ps = append(ps, l)
+	}
+	r.processes = make(map[*localLambda]struct{})
+	r.idle = make(map[string][]*localLambda)
+	r.mu.Unlock()
+	for _, l := range ps {
+		l.stop()
+	}
+}
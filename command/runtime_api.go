@@ -0,0 +1,287 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"io/ioutil"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/lambda/messages"
+
+	"github.com/ysugimoto/ginger/entity"
+)
+
+// Lambda Runtime API version which is used as path prefix
+const runtimeAPIVersion = "2018-06-01"
+
+// Default function timeout of AWS Lambda
+const defaultLambdaTimeout = 3 * time.Second
+
+// Dummy function ARN which is passed to local lambda
+const localFunctionArn = "arn:aws:lambda:us-east-1:000000000000:function:%s"
+
+// runtimeInvocation is the struct of an invocation which is passed via Runtime API.
+type runtimeInvocation struct {
+	id            string
+	payload       []byte
+	clientContext []byte
+	deadline      time.Time
+	result        chan *messages.InvokeResponse
+}
+
+// runtimeAPI is the local HTTP server which emulates Lambda Runtime API for a process.
+// Custom runtime (provided.al2, provided.al2023) function polls next invocation from this server,
+// and posts the response or error.
+// See https://docs.aws.amazon.com/lambda/latest/dg/runtimes-api.html
+type runtimeAPI struct {
+	fn        *entity.Function
+	listener  net.Listener
+	server    *http.Server
+	next      chan *runtimeInvocation
+	ready     chan struct{}
+	closed    chan struct{}
+	readyOnce sync.Once
+	closeOnce sync.Once
+
+	mu        sync.Mutex
+	pending   map[string]*runtimeInvocation
+	initError *messages.InvokeResponse_Error
+}
+
+// startRuntimeAPI starts Runtime API server on free port.
+func startRuntimeAPI(fn *entity.Function) (*runtimeAPI, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	api := &runtimeAPI{
+		fn:       fn,
+		listener: ln,
+		next:     make(chan *runtimeInvocation),
+		ready:    make(chan struct{}),
+		closed:   make(chan struct{}),
+		pending:  make(map[string]*runtimeInvocation),
+	}
+	api.server = &http.Server{
+		Handler: api,
+	}
+	go api.server.Serve(ln)
+	return api, nil
+}
+
+// address returns "host:port" which is set as AWS_LAMBDA_RUNTIME_API.
+func (api *runtimeAPI) address() string {
+	return api.listener.Addr().String()
+}
+
+// port returns listening port.
+func (api *runtimeAPI) port() string {
+	return fmt.Sprint(api.listener.Addr().(*net.TCPAddr).Port)
+}
+
+// isReady returns true if function has started to poll next invocation.
+func (api *runtimeAPI) isReady() bool {
+	select {
+	case <-api.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// close shuts down the server.
+func (api *runtimeAPI) close() {
+	api.closeOnce.Do(func() {
+		close(api.closed)
+		api.server.Close()
+	})
+}
+
+// ServeHTTP routes Runtime API request.
+func (api *runtimeAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	prefix := "/" + runtimeAPIVersion + "/runtime/"
+	if !strings.HasPrefix(req.URL.Path, prefix) {
+		http.NotFound(w, req)
+		return
+	}
+	path := strings.Split(strings.TrimPrefix(req.URL.Path, prefix), "/")
+	switch {
+	case req.Method == http.MethodGet && len(path) == 2 && path[0] == "invocation" && path[1] == "next":
+		api.handleNext(w, req)
+	case req.Method == http.MethodPost && len(path) == 3 && path[0] == "invocation" && path[2] == "response":
+		api.handleResponse(w, req, path[1])
+	case req.Method == http.MethodPost && len(path) == 3 && path[0] == "invocation" && path[2] == "error":
+		api.handleError(w, req, path[1])
+	case req.Method == http.MethodPost && len(path) == 2 && path[0] == "init" && path[1] == "error":
+		api.handleInitError(w, req)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// handleNext blocks until next invocation comes, and passes event to the function.
+func (api *runtimeAPI) handleNext(w http.ResponseWriter, req *http.Request) {
+	api.readyOnce.Do(func() {
+		close(api.ready)
+	})
+	var inv *runtimeInvocation
+	select {
+	case inv = <-api.next:
+	case <-api.closed:
+		return
+	case <-req.Context().Done():
+		return
+	}
+
+	api.mu.Lock()
+	api.pending[inv.id] = inv
+	api.mu.Unlock()
+
+	w.Header().Set("Lambda-Runtime-Aws-Request-Id", inv.id)
+	w.Header().Set("Lambda-Runtime-Deadline-Ms", strconv.FormatInt(inv.deadline.UnixNano()/int64(time.Millisecond), 10))
+	w.Header().Set("Lambda-Runtime-Invoked-Function-Arn", fmt.Sprintf(localFunctionArn, api.fn.Name))
+	w.Header().Set("Lambda-Runtime-Trace-Id", fmt.Sprintf("Root=1-%x-%024x;Parent=%016x;Sampled=0", time.Now().Unix(), time.Now().UnixNano(), time.Now().UnixNano()))
+	if len(inv.clientContext) > 0 {
+		w.Header().Set("Lambda-Runtime-Client-Context", string(inv.clientContext))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(inv.payload)
+}
+
+// handleResponse receives function response for the invocation.
+func (api *runtimeAPI) handleResponse(w http.ResponseWriter, req *http.Request, id string) {
+	inv := api.takePending(id)
+	if inv == nil {
+		respondRuntimeAPI(w, http.StatusBadRequest, "InvalidRequestID", "Invalid request ID")
+		return
+	}
+	payload, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		inv.result <- &messages.InvokeResponse{
+			Error: &messages.InvokeResponse_Error{
+				Type:    "Runtime.ResponseReadError",
+				Message: err.Error(),
+			},
+		}
+	} else {
+		inv.result <- &messages.InvokeResponse{
+			Payload: payload,
+		}
+	}
+	respondRuntimeAPI(w, http.StatusAccepted, "", "")
+}
+
+// handleError receives function error for the invocation.
+func (api *runtimeAPI) handleError(w http.ResponseWriter, req *http.Request, id string) {
+	inv := api.takePending(id)
+	if inv == nil {
+		respondRuntimeAPI(w, http.StatusBadRequest, "InvalidRequestID", "Invalid request ID")
+		return
+	}
+	inv.result <- &messages.InvokeResponse{
+		Error: readRuntimeError(req),
+	}
+	respondRuntimeAPI(w, http.StatusAccepted, "", "")
+}
+
+// handleInitError receives function initialization error.
+// Function process will exit after reporting it.
+func (api *runtimeAPI) handleInitError(w http.ResponseWriter, req *http.Request) {
+	api.mu.Lock()
+	api.initError = readRuntimeError(req)
+	api.mu.Unlock()
+	respondRuntimeAPI(w, http.StatusAccepted, "", "")
+}
+
+// takePending removes invocation from pending list and returns it.
+func (api *runtimeAPI) takePending(id string) *runtimeInvocation {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	inv, ok := api.pending[id]
+	if !ok {
+		return nil
+	}
+	delete(api.pending, id)
+	return inv
+}
+
+// reportedInitError returns initialization error if function reported.
+func (api *runtimeAPI) reportedInitError() *messages.InvokeResponse_Error {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.initError
+}
+
+// invoke passes payload to the function and waits for the result.
+// done channel should be closed when function process exits.
+func (api *runtimeAPI) invoke(source, clientContext []byte, done chan struct{}) (*messages.InvokeResponse, error) {
+	timeout := time.Duration(api.fn.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultLambdaTimeout
+	}
+	inv := &runtimeInvocation{
+		id:            fmt.Sprintf("ginger-invoke-%d", time.Now().UnixNano()),
+		payload:       source,
+		clientContext: clientContext,
+		deadline:      time.Now().Add(timeout),
+		result:        make(chan *messages.InvokeResponse, 1),
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case api.next <- inv:
+	case <-done:
+		return nil, exception("Local lambda %s exited before receiving invocation", api.fn.Name)
+	case <-timer.C:
+		return nil, exception("Local lambda %s didn't poll next invocation in %s", api.fn.Name, timeout)
+	}
+
+	select {
+	case resp := <-inv.result:
+		return resp, nil
+	case <-done:
+		return nil, exception("Local lambda %s exited while invocation %s", api.fn.Name, inv.id)
+	case <-timer.C:
+		return nil, exception("Task timed out after %.2f seconds", timeout.Seconds())
+	}
+}
+
+// readRuntimeError decodes error which is posted from function.
+func readRuntimeError(req *http.Request) *messages.InvokeResponse_Error {
+	e := &messages.InvokeResponse_Error{}
+	if buf, err := ioutil.ReadAll(req.Body); err != nil || json.Unmarshal(buf, e) != nil {
+		e.Message = "Function reported malformed error"
+	}
+	if e.Type == "" {
+		e.Type = req.Header.Get("Lambda-Runtime-Function-Error-Type")
+	}
+	if e.Type == "" {
+		e.Type = "Unhandled"
+	}
+	return e
+}
+
+// respondRuntimeAPI writes Runtime API JSON response.
+func respondRuntimeAPI(w http.ResponseWriter, status int, errorType, message string) {
+	body := map[string]string{
+		"status": "OK",
+	}
+	if errorType != "" {
+		body = map[string]string{
+			"errorType":    errorType,
+			"errorMessage": message,
+		}
+	}
+	buf, _ := json.Marshal(body)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(buf)
+}
@@ -99,7 +99,8 @@ func (s *Scheduler) Run(ctx *args.Context) error {
 	case SCHEDULERDELETE:
 		err = s.deleteScheduler(c, ctx)
 	case SCHEDULERDEPLOY:
-		err = NewDeploy().deploySchedulers(c, ctx)
+		d := NewDeploy()
+		err = d.report(d.deploySchedulers(c, ctx))
 	case SCHEDULERLIST:
 		err = s.listScheduler(c, ctx)
 	case SCHEDULERATTACH:
@@ -239,7 +240,7 @@ func (s *Scheduler) listScheduler(c *config.Config, ctx *args.Context) error {
 	}
 	line := strings.Repeat("=", w)
 	fmt.Println(line)
-	fmt.Printf("%-12s %-24s %-12s %-12s %-24s\n", "SchedulerName", "Expression", "Enable", "Functions")
+	fmt.Printf("%-12s %-24s %-12s %-24s\n", "SchedulerName", "Expression", "Enable", "Functions")
 	fmt.Println(line)
 	for i, sc := range scs {
 		f := ""
@@ -253,7 +254,7 @@ func (s *Scheduler) listScheduler(c *config.Config, ctx *args.Context) error {
 		if sc.Enable {
 			e = "enabled"
 		}
-		fmt.Printf("%-12s %-24s %-12s %-12s %-24s\n", sc.Name, sc.Expression, e, f)
+		fmt.Printf("%-12s %-24s %-12s %-24s\n", sc.Name, sc.Expression, e, f)
 		if i != len(scs)-1 {
 			fmt.Println(strings.Repeat("-", w))
 		}
@@ -294,9 +295,18 @@ func (s *Scheduler) attachScheduler(c *config.Config, ctx *args.Context) error {
 		return exception(err.Error())
 	}
 
-	if _, err := c.LoadFunction(fname); err != nil {
+	fn, err := c.LoadFunction(fname)
+	if err != nil {
 		return exception(err.Error())
 	}
+	// Target can be alias if function declares aliases
+	if len(fn.Aliases) > 0 {
+		choices := []string{fname}
+		for _, a := range fn.Aliases {
+			choices = append(choices, fname+":"+a.Name)
+		}
+		fname = input.Choice("Select target version", choices)
+	}
 
 	if sc.Functions == nil {
 		sc.Functions = make([]string, 0)
@@ -0,0 +1,486 @@
+package command
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"io/ioutil"
+	"net/http"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ysugimoto/go-args"
+
+	"github.com/ysugimoto/ginger/config"
+	"github.com/ysugimoto/ginger/entity"
+	"github.com/ysugimoto/ginger/logger"
+)
+
+// Default stage name of local API server
+const localStageName = "local"
+
+// Serve is the struct of local API Gateway emulator.
+// This struct will be dispatched on "ginger serve" subcommand.
+type Serve struct {
+	Command
+	log *logger.Logger
+
+	config    *config.Config
+	stage     *entity.Stage
+	binaries  map[string]string
+	functions map[string]*entity.Function
+	runtime   *localRuntime
+	mu        sync.RWMutex
+}
+
+func NewServe() *Serve {
+	log := logger.WithNamespace("ginger.serve")
+	return &Serve{
+		log:       log,
+		binaries:  make(map[string]string),
+		functions: make(map[string]*entity.Function),
+		runtime:   newLocalRuntime(log),
+	}
+}
+
+// Show serve command help.
+func (s *Serve) Help() string {
+	return commandHeader() + `
+serve - Run local API Gateway emulator.
+
+Usage:
+  $ ginger serve [options]
+
+Options:
+      --port  : Listen port (default=8080)
+  -s, --stage : Stage name to use stage variables (default=local)
+      --watch : Rebuild functions on source changes
+`
+}
+
+// Run the command.
+//
+// >>> doc
+//
+// ## Serve API locally
+//
+// Start local HTTP server which emulates API Gateway.
+//
+// ```
+// $ ginger serve [options]
+// ```
+//
+// | option  | description                                                        |
+// |:-------:|:-------------------------------------------------------------------|
+// | --port  | Listen port. default is 8080                                       |
+// | --stage | Stage name. stage variables are loaded from `stages/<stage>.toml` |
+// | --watch | Rebuild functions when `.go` files are changed                     |
+//
+// Requests are routed to functions by resources and their integrations, including `{param}` and `{proxy+}` segments.
+// Lambda integration receives the request as API Gateway proxy event, and responded proxy response is mapped to HTTP response.
+// S3 integration is served from local `storage/` directory.
+// All mounted functions are built on start up. Local Lambda process is started on first request (cold start) and reused for following requests (warm).
+// Concurrent requests run on separate processes which listen on their own free ports.
+//
+// <<< doc
+func (s *Serve) Run(ctx *args.Context) error {
+	c := config.Load()
+	if !c.Exists() {
+		s.log.Error("Configuration file could not load. Run `ginger init` before.")
+		return errors.New("")
+	}
+	s.config = c
+	err := s.serve(ctx)
+	if err != nil {
+		s.log.Error(err.Error())
+		debugTrace(err)
+	}
+	return err
+}
+
+func (s *Serve) serve(ctx *args.Context) error {
+	s.stage = &entity.Stage{
+		Name:      localStageName,
+		Variables: make(map[string]string),
+	}
+	if name := ctx.String("stage"); name != "" {
+		stg, err := s.config.LoadStage(name)
+		if err != nil {
+			return exception("Stage %s couldn't find in your project.", name)
+		}
+		s.stage = stg
+	}
+
+	tmpDir, err := ioutil.TempDir("", "ginger-local-serve")
+	if err != nil {
+		return exception("Failed to create temporary directory: %s", err.Error())
+	}
+	defer os.RemoveAll(tmpDir)
+	defer s.runtime.shutdown()
+	if err := s.buildFunctions(tmpDir); err != nil {
+		return err
+	}
+	if ctx.Has("watch") {
+		watchCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		s.watchFunctions(watchCtx)
+	}
+
+	port := ctx.Int("port")
+	if port == 0 {
+		port = 8080
+	}
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", port),
+		Handler: s,
+	}
+	go func() {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+		<-ch
+		s.log.Print("Shutting down local API server...")
+		timeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(timeout)
+	}()
+
+	s.log.Infof("Local API server listening on http://127.0.0.1:%d (stage: %s)\n", port, s.stage.Name)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return exception("Failed to start local API server: %s", err.Error())
+	}
+	return nil
+}
+
+// buildFunctions builds all mounted functions into temporary directory.
+func (s *Serve) buildFunctions(dir string) error {
+	for _, r := range s.config.Resources {
+		for _, ig := range r.GetIntegrations() {
+			if ig.IntegrationType != "lambda" {
+				continue
+			}
+			// Local server always runs current sources even if integration is qualified by alias
+			name, _ := entity.SplitQualifier(*ig.LambdaFunction)
+			if _, ok := s.binaries[name]; ok {
+				continue
+			}
+			fn, err := s.config.LoadFunction(name)
+			if err != nil {
+				return exception("Function %s couldn't find in your project.", name)
+			}
+			s.log.Printf("Building function %s...\n", name)
+			bin := filepath.Join(dir, name)
+			if err := execGoCommand(context.Background(), s.config, name, "build", localBuildArguments(fn, bin)); err != nil {
+				return exception("Failed to build %s binary: %s ", name, err.Error())
+			}
+			s.binaries[name] = bin
+			s.functions[name] = fn
+		}
+	}
+	return nil
+}
+
+// watchFunctions rebuilds functions when sources are changed.
+// If local packages are changed, all functions are rebuilt because we don't know which function depends on it.
+func (s *Serve) watchFunctions(ctx context.Context) {
+	dirs := localPackageDirs(s.config)
+	for name := range s.binaries {
+		dirs = append(dirs, filepath.Join(s.config.FunctionPath, name))
+	}
+	watcher := newFileWatcher(dirs)
+	go watcher.watch(ctx, func(changed []string) {
+		s.log.Warnf("Detected changes: %s\n", strings.Join(changed, ", "))
+		targets := map[string]struct{}{}
+		for _, file := range changed {
+			name := ""
+			if rel, err := filepath.Rel(s.config.FunctionPath, file); err == nil && !strings.HasPrefix(rel, "..") {
+				name = strings.Split(filepath.ToSlash(rel), "/")[0]
+			}
+			if _, ok := s.binaries[name]; !ok {
+				for n := range s.binaries {
+					targets[n] = struct{}{}
+				}
+				break
+			}
+			targets[name] = struct{}{}
+		}
+
+		// Block invocations while rebuilding
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for name := range targets {
+			s.log.Printf("Rebuilding function %s...\n", name)
+			if err := execGoCommand(context.Background(), s.config, name, "build", localBuildArguments(s.functions[name], s.binaries[name])); err != nil {
+				s.log.Errorf("Failed to build %s binary: %s. Previous build is still used.\n", name, err.Error())
+				continue
+			}
+			s.runtime.reload(name)
+			s.log.Infof("Function %s reloaded.\n", name)
+		}
+	})
+	s.log.Warn("Watching function sources for changes.")
+}
+
+// ServeHTTP routes request to integration.
+func (s *Serve) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	status := s.handle(w, req)
+	s.log.Printf("%s %s %d (%s)\n", req.Method, req.URL.Path, status, time.Since(start))
+}
+
+func (s *Serve) handle(w http.ResponseWriter, req *http.Request) int {
+	r, params := matchResource(s.config.Resources, req.URL.Path)
+	if r == nil {
+		return respondMessage(w, http.StatusNotFound, "Not Found")
+	}
+	ig := r.GetIntegration(req.Method)
+	if ig == nil {
+		ig = r.GetIntegration("ANY")
+	}
+	if ig == nil {
+		return respondMessage(w, http.StatusForbidden, "Missing Authentication Token")
+	}
+
+	switch ig.IntegrationType {
+	case "lambda":
+		return s.handleLambda(w, req, r, ig, params)
+	case "s3":
+		return s.handleStorage(w, req, r, ig, params)
+	default:
+		return respondMessage(w, http.StatusInternalServerError, "Internal server error")
+	}
+}
+
+// handleLambda invokes local function with API Gateway proxy event, and maps the proxy response.
+func (s *Serve) handleLambda(w http.ResponseWriter, req *http.Request, r *entity.Resource, ig *entity.Integration, params map[string]string) int {
+	event, err := s.proxyRequest(req, r, params)
+	if err != nil {
+		s.log.Errorf("Failed to read request: %s\n", err.Error())
+		return respondMessage(w, http.StatusBadRequest, "Bad Request")
+	}
+	payload, _ := json.Marshal(event)
+
+	name, _ := entity.SplitQualifier(*ig.LambdaFunction)
+	// Invocations run concurrently, but wait while functions are rebuilding
+	s.mu.RLock()
+	resp, err := s.runtime.invoke(s.functions[name], s.binaries[name], payload, []byte{})
+	s.mu.RUnlock()
+	if err != nil {
+		s.log.Errorf("Failed to invoke local Lambda: %s\n", err.Error())
+		return respondMessage(w, http.StatusBadGateway, "Internal server error")
+	} else if resp.Error != nil {
+		s.log.Errorf("Lambda responded error:\nType: %s\nMessage: %s\n", resp.Error.Type, resp.Error.Message)
+		return respondMessage(w, http.StatusBadGateway, "Internal server error")
+	}
+
+	proxy := events.APIGatewayProxyResponse{}
+	if err := json.Unmarshal(resp.Payload, &proxy); err != nil || proxy.StatusCode == 0 {
+		s.log.Error("Lambda responded malformed proxy response.")
+		return respondMessage(w, http.StatusBadGateway, "Internal server error")
+	}
+	for k, v := range proxy.Headers {
+		w.Header().Set(k, v)
+	}
+	for k, vs := range proxy.MultiValueHeaders {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	body := []byte(proxy.Body)
+	if proxy.IsBase64Encoded {
+		if body, err = base64.StdEncoding.DecodeString(proxy.Body); err != nil {
+			s.log.Error("Lambda responded invalid base64 body.")
+			return respondMessage(w, http.StatusBadGateway, "Internal server error")
+		}
+	}
+	w.WriteHeader(proxy.StatusCode)
+	w.Write(body)
+	return proxy.StatusCode
+}
+
+// proxyRequest translates HTTP request to API Gateway proxy event.
+func (s *Serve) proxyRequest(req *http.Request, r *entity.Resource, params map[string]string) (*events.APIGatewayProxyRequest, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	event := &events.APIGatewayProxyRequest{
+		Resource:                        r.Path,
+		Path:                            req.URL.Path,
+		HTTPMethod:                      req.Method,
+		Headers:                         make(map[string]string),
+		MultiValueHeaders:               make(map[string][]string),
+		QueryStringParameters:           make(map[string]string),
+		MultiValueQueryStringParameters: make(map[string][]string),
+		PathParameters:                  params,
+		StageVariables:                  s.stage.Variables,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Stage:        s.stage.Name,
+			RequestID:    fmt.Sprintf("ginger-serve-%d", time.Now().UnixNano()),
+			ResourcePath: r.Path,
+			HTTPMethod:   req.Method,
+			Identity: events.APIGatewayRequestIdentity{
+				SourceIP:  strings.Split(req.RemoteAddr, ":")[0],
+				UserAgent: req.UserAgent(),
+			},
+		},
+	}
+	for k, vs := range req.Header {
+		event.Headers[k] = vs[len(vs)-1]
+		event.MultiValueHeaders[k] = vs
+	}
+	if req.Host != "" {
+		event.Headers["Host"] = req.Host
+		event.MultiValueHeaders["Host"] = []string{req.Host}
+	}
+	for k, vs := range req.URL.Query() {
+		event.QueryStringParameters[k] = vs[len(vs)-1]
+		event.MultiValueQueryStringParameters[k] = vs
+	}
+	if utf8.Valid(body) {
+		event.Body = string(body)
+	} else {
+		event.Body = base64.StdEncoding.EncodeToString(body)
+		event.IsBase64Encoded = true
+	}
+	return event, nil
+}
+
+// handleStorage serves file from local storage directory.
+// File path is taken from the greedy path variable of the resource.
+func (s *Serve) handleStorage(w http.ResponseWriter, req *http.Request, r *entity.Resource, ig *entity.Integration, params map[string]string) int {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return respondMessage(w, http.StatusForbidden, "Missing Authentication Token")
+	}
+	bucketPath := strings.Trim(*ig.BucketPath, "/")
+	if bucketPath != s.config.S3BucketName && !strings.HasPrefix(bucketPath, s.config.S3BucketName+"/") {
+		s.log.Warnf("External bucket %s couldn't serve locally.\n", bucketPath)
+		return respondMessage(w, http.StatusNotFound, "Not Found")
+	}
+	dir := strings.TrimPrefix(bucketPath, s.config.S3BucketName)
+	file := filepath.Join(s.config.StoragePath, dir, filepath.FromSlash(filepath.Clean("/"+params[greedyParamName(r.Path)])))
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return respondMessage(w, http.StatusNotFound, "Not Found")
+	}
+	if t := mime.TypeByExtension(filepath.Ext(file)); t != "" {
+		w.Header().Set("Content-Type", t)
+	} else {
+		w.Header().Set("Content-Type", http.DetectContentType(buf))
+	}
+	w.WriteHeader(http.StatusOK)
+	if req.Method == http.MethodGet {
+		w.Write(buf)
+	}
+	return http.StatusOK
+}
+
+// respondMessage responds API Gateway like JSON message.
+func respondMessage(w http.ResponseWriter, status int, message string) int {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "{\"message\":\"%s\"}", message)
+	return status
+}
+
+// matchResource finds resource which matches request path, and returns path parameters.
+// Like API Gateway, candidates are compared segment by segment from left to right,
+// and static segment takes precedence over "{param}", then "{proxy+}" at each segment.
+func matchResource(resources []*entity.Resource, path string) (*entity.Resource, map[string]string) {
+	var matched *entity.Resource
+	var matchedParams map[string]string
+	var matchedRanks []int
+
+	segments := splitPath(path)
+	for _, r := range resources {
+		if len(r.GetIntegrations()) == 0 {
+			continue
+		}
+		params, ranks, ok := matchSegments(splitPath(r.Path), segments)
+		if ok && (matched == nil || compareRanks(ranks, matchedRanks) > 0) {
+			matched = r
+			matchedParams = params
+			matchedRanks = ranks
+		}
+	}
+	return matched, matchedParams
+}
+
+const (
+	rankGreedy = iota
+	rankVariable
+	rankStatic
+)
+
+// matchSegments matches resource segments with request segments.
+// Returned ranks indicate how each resource segment is matched, greedy path variable is the last rank if exists.
+func matchSegments(patterns, segments []string) (map[string]string, []int, bool) {
+	params := map[string]string{}
+	ranks := []int{}
+	for i, p := range patterns {
+		if isGreedyParam(p) {
+			// Greedy path variable requires at least one segment
+			if i >= len(segments) {
+				return nil, nil, false
+			}
+			params[strings.Trim(p, "{+}")] = strings.Join(segments[i:], "/")
+			return params, append(ranks, rankGreedy), true
+		} else if i >= len(segments) {
+			return nil, nil, false
+		} else if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+			params[strings.Trim(p, "{}")] = segments[i]
+			ranks = append(ranks, rankVariable)
+		} else if p == segments[i] {
+			ranks = append(ranks, rankStatic)
+		} else {
+			return nil, nil, false
+		}
+	}
+	if len(patterns) != len(segments) {
+		return nil, nil, false
+	}
+	return params, ranks, true
+}
+
+// compareRanks compares ranks of matched resources from left segment,
+// and returns positive value if a takes precedence over b, negative value if b does, otherwise zero.
+func compareRanks(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return len(a) - len(b)
+}
+
+// isGreedyParam returns true if segment is greedy path variable like "{proxy+}".
+func isGreedyParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "+}")
+}
+
+// greedyParamName returns greedy path variable name of the resource path, or empty if not exists.
+func greedyParamName(path string) string {
+	segments := splitPath(path)
+	if len(segments) == 0 || !isGreedyParam(segments[len(segments)-1]) {
+		return ""
+	}
+	return strings.Trim(segments[len(segments)-1], "{+}")
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return []string{}
+	}
+	return strings.Split(path, "/")
+}
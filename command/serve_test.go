@@ -0,0 +1,133 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ysugimoto/ginger/entity"
+)
+
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		path     string
+		params   map[string]string
+		ranks    []int
+		expectOk bool
+	}{
+		{
+			name:     "static path",
+			pattern:  "/users/list",
+			path:     "/users/list",
+			params:   map[string]string{},
+			ranks:    []int{rankStatic, rankStatic},
+			expectOk: true,
+		},
+		{
+			name:     "path variable",
+			pattern:  "/users/{id}",
+			path:     "/users/10",
+			params:   map[string]string{"id": "10"},
+			ranks:    []int{rankStatic, rankVariable},
+			expectOk: true,
+		},
+		{
+			name:     "greedy path variable",
+			pattern:  "/static/{proxy+}",
+			path:     "/static/css/main.css",
+			params:   map[string]string{"proxy": "css/main.css"},
+			ranks:    []int{rankStatic, rankGreedy},
+			expectOk: true,
+		},
+		{
+			name:    "greedy path variable requires segment",
+			pattern: "/static/{proxy+}",
+			path:    "/static",
+		},
+		{
+			name:    "static segment is different",
+			pattern: "/users/{id}",
+			path:    "/groups/10",
+		},
+		{
+			name:    "request path is shorter",
+			pattern: "/users/{id}",
+			path:    "/users",
+		},
+		{
+			name:    "request path is longer",
+			pattern: "/users/{id}",
+			path:    "/users/10/posts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, ranks, ok := matchSegments(splitPath(tt.pattern), splitPath(tt.path))
+			if ok != tt.expectOk {
+				t.Fatalf("expected match %t, got %t", tt.expectOk, ok)
+			} else if !ok {
+				return
+			}
+			if !reflect.DeepEqual(ranks, tt.ranks) {
+				t.Errorf("expected ranks %v, got %v", tt.ranks, ranks)
+			}
+			if !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("expected params %v, got %v", tt.params, params)
+			}
+		})
+	}
+}
+
+func TestMatchResource(t *testing.T) {
+	resources := []*entity.Resource{}
+	for _, path := range []string{"/users/me", "/users/{id}", "/users/{proxy+}", "/{type}/{id}/b", "/{type}/{id}"} {
+		resources = append(resources, &entity.Resource{
+			Path:         path,
+			Integrations: map[string]*entity.Integration{"GET": {}},
+		})
+	}
+
+	tests := []struct {
+		path   string
+		expect string
+	}{
+		{path: "/users/me", expect: "/users/me"},
+		{path: "/users/10", expect: "/users/{id}"},
+		{path: "/users/a/b", expect: "/users/{proxy+}"},
+		{path: "/groups/a/b", expect: "/{type}/{id}/b"},
+		{path: "/groups/a", expect: "/{type}/{id}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			r, _ := matchResource(resources, tt.path)
+			if r == nil {
+				t.Fatalf("expected %s matches, got nothing", tt.expect)
+			} else if r.Path != tt.expect {
+				t.Errorf("expected %s matches, got %s", tt.expect, r.Path)
+			}
+		})
+	}
+}
+
+func TestGreedyParamName(t *testing.T) {
+	tests := []struct {
+		path   string
+		expect string
+	}{
+		{path: "/static/{proxy+}", expect: "proxy"},
+		{path: "/assets/{key+}", expect: "key"},
+		{path: "/users/{id}", expect: ""},
+		{path: "/", expect: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if actual := greedyParamName(tt.path); actual != tt.expect {
+				t.Errorf("expected %q, got %q", tt.expect, actual)
+			}
+		})
+	}
+}
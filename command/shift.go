@@ -0,0 +1,139 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ysugimoto/ginger/entity"
+	"github.com/ysugimoto/ginger/logger"
+	"github.com/ysugimoto/ginger/request"
+)
+
+// Interval to check metrics and alarms while baking traffic
+const shiftCheckInterval = time.Minute
+
+// CloudWatch Lambda metrics arrive 1-3 minutes late, so keep checking for this period after each bake interval
+// before traffic is shifted further
+const shiftMetricsDelay = 3 * shiftCheckInterval
+
+// trafficShift is the struct which shifts alias traffic to new version step by step.
+// On each step, new version's metrics and configured alarms are watched during bake period,
+// and alias is rolled back to previous version if either of them is breached.
+type trafficShift struct {
+	lambda     *request.LambdaRequest
+	cw         *request.CloudWatchRequest
+	log        *logger.Logger
+	fn         *entity.Function
+	deployment *entity.FunctionDeployment
+	strategy   string
+}
+
+func newTrafficShift(
+	lambda *request.LambdaRequest,
+	cw *request.CloudWatchRequest,
+	log *logger.Logger,
+	fn *entity.Function,
+	strategy string,
+) *trafficShift {
+	return &trafficShift{
+		lambda:     lambda,
+		cw:         cw,
+		log:        log,
+		fn:         fn,
+		deployment: fn.Deployment,
+		strategy:   strategy,
+	}
+}
+
+// run shifts traffic of alias to the version.
+// If alias doesn't exist or already points the version, alias just points the version.
+func (t *trafficShift) run(version string) error {
+	alias := t.deployment.Alias
+	current, err := t.lambda.GetAlias(t.fn.Name, alias)
+	if err != nil {
+		t.log.Printf("Alias %s doesn't exist, create it without traffic shifting\n", alias)
+		return t.lambda.PutAlias(t.fn.Name, alias, version)
+	}
+	previous := *current.FunctionVersion
+	if previous == version {
+		t.log.Printf("Alias %s already points version %s\n", alias, version)
+		return nil
+	}
+
+	interval := time.Duration(t.deployment.GetInterval()) * time.Minute
+	for _, percentage := range t.deployment.Steps(t.strategy) {
+		if percentage >= 100 {
+			break
+		}
+		t.log.Printf("Shifting %.0f%% traffic of %s:%s to version %s...\n", percentage, t.fn.Name, alias, version)
+		if err := t.lambda.RouteAlias(t.fn.Name, alias, previous, version, percentage/100); err != nil {
+			return t.rollback(previous, err)
+		}
+		if err := t.bake(version, interval); err != nil {
+			return t.rollback(previous, err)
+		}
+	}
+
+	t.log.Printf("Shifting all traffic of %s:%s to version %s...\n", t.fn.Name, alias, version)
+	if err := t.lambda.RouteAlias(t.fn.Name, alias, version, "", 0); err != nil {
+		return t.rollback(previous, err)
+	}
+	return nil
+}
+
+// bake watches new version while traffic is shifted, and returns error if breached.
+// Metrics of the last minutes in interval are checked after they arrive, so bake takes interval and metrics delay.
+func (t *trafficShift) bake(version string, interval time.Duration) error {
+	start := time.Now()
+	end := start.Add(interval + shiftMetricsDelay)
+	t.log.Printf("Baking until %s...\n", end.Format("15:04:05"))
+	for {
+		wait := shiftCheckInterval
+		if remain := time.Until(end); remain < wait {
+			wait = remain
+		}
+		time.Sleep(wait)
+		if err := t.check(version, start); err != nil {
+			return err
+		} else if !time.Now().Before(end) {
+			return nil
+		}
+	}
+}
+
+// check checks errors and throttles of new version since start, and alarm states.
+func (t *trafficShift) check(version string, start time.Time) error {
+	thresholds := map[string]float64{
+		"Errors":    t.deployment.ErrorThreshold,
+		"Throttles": t.deployment.ThrottleThreshold,
+	}
+	for _, metric := range []string{"Errors", "Throttles"} {
+		sum, err := t.cw.SumFunctionMetric(t.fn.Name, t.deployment.Alias, version, metric, start, time.Now())
+		if err != nil {
+			return fmt.Errorf("Failed to get %s metric: %s", metric, err.Error())
+		} else if sum > thresholds[metric] {
+			return fmt.Errorf("%s of version %s is %.0f, exceeds threshold %.0f", metric, version, sum, thresholds[metric])
+		}
+	}
+	if len(t.deployment.Alarms) == 0 {
+		return nil
+	}
+	alarming, err := t.cw.AlarmingAlarms(t.deployment.Alarms)
+	if err != nil {
+		return fmt.Errorf("Failed to describe alarms: %s", err.Error())
+	} else if len(alarming) > 0 {
+		return fmt.Errorf("Alarm %s is in ALARM state", strings.Join(alarming, ", "))
+	}
+	return nil
+}
+
+// rollback points alias to previous version with all traffic.
+func (t *trafficShift) rollback(previous string, reason error) error {
+	t.log.Errorf("%s. Rolling back %s:%s to version %s...\n", reason.Error(), t.fn.Name, t.deployment.Alias, previous)
+	if err := t.lambda.RouteAlias(t.fn.Name, t.deployment.Alias, previous, "", 0); err != nil {
+		return exception("Failed to roll back %s:%s: %s", t.fn.Name, t.deployment.Alias, err.Error())
+	}
+	t.log.Warnf("Alias %s:%s has been rolled back to version %s\n", t.fn.Name, t.deployment.Alias, previous)
+	return exception("Deployment of %s was rolled back: %s", t.fn.Name, reason.Error())
+}
@@ -55,7 +55,8 @@ Operation:
   help   : Show this help
 
 Options:
-  -n, --name : [all] Stage name (required)
+  -n, --name    : [all] Stage name (required)
+      --message : [deploy] Deployment description
 `
 }
 
@@ -81,7 +82,7 @@ func (s *Stage) Run(ctx *args.Context) error {
 	case STAGEDELETE:
 		err = s.deleteStage(c, ctx)
 	case STAGEDEPLOY:
-		// err = NewDeploy().deployStage(c, ctx)
+		err = s.deployStage(c, ctx)
 	case STAGELIST:
 		err = s.listStage(c, ctx)
 	default:
@@ -145,6 +146,16 @@ func (s *Stage) deleteStage(c *config.Config, ctx *args.Context) error {
 	return nil
 }
 
+// deployStage creates deployment and syncs stage variables to API Gateway.
+func (s *Stage) deployStage(c *config.Config, ctx *args.Context) error {
+	name := ctx.String("name")
+	if name == "" {
+		return exception("Stage name didn't supplied. Run with --name option.")
+	}
+	d := NewDeploy()
+	return d.report(d.deployStageByName(c, name, ctx.String("message")))
+}
+
 // listStage shows registered stages.
 func (s *Stage) listStage(c *config.Config, ctx *args.Context) error {
 	api := request.NewAPIGateway(c)
@@ -65,7 +65,8 @@ func (s *Storage) Run(ctx *args.Context) error {
 
 	switch ctx.At(1) {
 	case STORAGEDEPLOY:
-		err = NewDeploy().deployStorage(c, ctx)
+		d := NewDeploy()
+		err = d.report(d.deployStorage(c, ctx))
 	case STORAGEMOUNT:
 		err = s.mountStorage(c, ctx)
 	case STORAGEUNMOUNT:
@@ -0,0 +1,99 @@
+package command
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"path/filepath"
+
+	"github.com/ysugimoto/ginger/config"
+)
+
+// Interval of polling file changes
+const watchInterval = 500 * time.Millisecond
+
+// fileWatcher is the struct which watches .go file changes under directories.
+// We detect changes by polling modification time to work on any platform without additional dependencies.
+type fileWatcher struct {
+	dirs     []string
+	snapshot map[string]time.Time
+}
+
+func newFileWatcher(dirs []string) *fileWatcher {
+	w := &fileWatcher{
+		dirs: dirs,
+	}
+	w.snapshot = w.scan()
+	return w
+}
+
+// scan collects modification times of .go files.
+func (w *fileWatcher) scan() map[string]time.Time {
+	files := map[string]time.Time{}
+	for _, dir := range w.dirs {
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			} else if info.IsDir() {
+				// Skip hidden directories like .git
+				if path != dir && strings.HasPrefix(info.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			} else if filepath.Ext(path) == ".go" {
+				files[path] = info.ModTime()
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+// changes returns changed, added or removed files since last scan.
+func (w *fileWatcher) changes() []string {
+	current := w.scan()
+	changed := []string{}
+	for path, mtime := range current {
+		if prev, ok := w.snapshot[path]; !ok || !prev.Equal(mtime) {
+			changed = append(changed, path)
+		}
+	}
+	for path := range w.snapshot {
+		if _, ok := current[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	w.snapshot = current
+	sort.Strings(changed)
+	return changed
+}
+
+// watch calls handler with changed files until context is canceled.
+func (w *fileWatcher) watch(ctx context.Context, handler func(changed []string)) {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if changed := w.changes(); len(changed) > 0 {
+				handler(changed)
+			}
+		}
+	}
+}
+
+// localPackageDirs resolves source directories of local_packages.
+func localPackageDirs(c *config.Config) []string {
+	dirs := []string{}
+	for _, pkg := range c.LocalPackages {
+		if dir := localPackageDir(c, pkg); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
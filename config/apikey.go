@@ -0,0 +1,65 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/ysugimoto/ginger/entity"
+	"github.com/ysugimoto/ginger/input"
+)
+
+var ApiKeyNotFound = errors.New("API key not found")
+var UsagePlanNotFound = errors.New("Usage plan not found")
+
+func (c *Config) LoadApiKey(name string) (*entity.ApiKey, error) {
+	for _, k := range c.ApiKeys {
+		if k.Name == name {
+			return k, nil
+		}
+	}
+	return nil, ApiKeyNotFound
+}
+
+func (c *Config) DeleteApiKey(name string) error {
+	for i, k := range c.ApiKeys {
+		if k.Name == name {
+			c.ApiKeys = append(c.ApiKeys[0:i], c.ApiKeys[i+1:]...)
+			// Also detach from usage plans
+			for _, u := range c.UsagePlans {
+				u.DetachApiKey(name)
+			}
+			return nil
+		}
+	}
+	return ApiKeyNotFound
+}
+
+func (c *Config) ChooseApiKey() string {
+	choose := []string{}
+	for _, k := range c.ApiKeys {
+		choose = append(choose, k.Name)
+	}
+	if len(choose) == 0 {
+		return ""
+	}
+	return input.Choice("Select target API key", choose)
+}
+
+func (c *Config) LoadUsagePlan(name string) (*entity.UsagePlan, error) {
+	for _, u := range c.UsagePlans {
+		if u.Name == name {
+			return u, nil
+		}
+	}
+	return nil, UsagePlanNotFound
+}
+
+func (c *Config) ChooseUsagePlan() string {
+	choose := []string{}
+	for _, u := range c.UsagePlans {
+		choose = append(choose, u.Name)
+	}
+	if len(choose) == 0 {
+		return ""
+	}
+	return input.Choice("Select target usage plan", choose)
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/ysugimoto/ginger/entity"
+	"github.com/ysugimoto/ginger/input"
+)
+
+var AuthorizerNotFound = errors.New("Authorizer not found")
+
+func (c *Config) LoadAuthorizer(name string) (*entity.Authorizer, error) {
+	for _, a := range c.Authorizers {
+		if a.Name == name {
+			return a, nil
+		}
+	}
+	return nil, AuthorizerNotFound
+}
+
+func (c *Config) DeleteAuthorizer(name string) error {
+	for i, a := range c.Authorizers {
+		if a.Name == name {
+			c.Authorizers = append(c.Authorizers[0:i], c.Authorizers[i+1:]...)
+			return nil
+		}
+	}
+	return AuthorizerNotFound
+}
+
+func (c *Config) ChooseAuthorizer() string {
+	choose := []string{}
+	for _, a := range c.Authorizers {
+		choose = append(choose, a.Name)
+	}
+	if len(choose) == 0 {
+		return ""
+	}
+	return input.Choice("Select target authorizer", choose)
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Code upload modes which can be set as code_upload
+const (
+	CodeUploadAuto = "auto"
+	CodeUploadS3   = "s3"
+)
+
+// DefaultCodeUploadThreshold is the package size in MB to upload via S3.
+// AWS Lambda accepts zip up to 50MB on direct upload.
+const DefaultCodeUploadThreshold = 50
+
+// S3 key prefixes which function and layer packages are uploaded under
+const (
+	FunctionArtifactPrefix = ".ginger/functions/"
+	LayerArtifactPrefix    = ".ginger/layers/"
+)
+
+// UploadCodeViaS3() returns true if function package should be uploaded to S3 instead of inline zip.
+// If code_upload is "s3", always upload via S3, otherwise upload when package exceeds code_upload_threshold.
+func (c *Config) UploadCodeViaS3(size int) bool {
+	if c.CodeUpload == CodeUploadS3 {
+		return true
+	}
+	threshold := c.CodeUploadThreshold
+	if threshold <= 0 {
+		threshold = DefaultCodeUploadThreshold
+	}
+	return int64(size) > threshold*1024*1024
+}
+
+// FunctionArtifactKey() returns versioned S3 key for function package.
+// Key consists of upload time and content hash, so keys are ordered by time and each deployment is kept as history.
+func FunctionArtifactKey(name string, zipBytes []byte) string {
+	return artifactKey(FunctionArtifactPrefix, name, zipBytes)
+}
+
+// LayerArtifactKey() returns versioned S3 key for layer package.
+func LayerArtifactKey(name string, zipBytes []byte) string {
+	return artifactKey(LayerArtifactPrefix, name, zipBytes)
+}
+
+func artifactKey(prefix, name string, zipBytes []byte) string {
+	sum := sha256.Sum256(zipBytes)
+	return fmt.Sprintf(
+		"%s%s/%s-%s.zip",
+		prefix,
+		name,
+		time.Now().UTC().Format("20060102T150405Z"),
+		hex.EncodeToString(sum[:])[0:12],
+	)
+}
+
+// IsArtifactKey() returns true if S3 key is function or layer package which ginger uploaded.
+func IsArtifactKey(key string) bool {
+	return strings.HasPrefix(key, FunctionArtifactPrefix) || strings.HasPrefix(key, LayerArtifactPrefix)
+}
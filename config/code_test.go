@@ -0,0 +1,74 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUploadCodeViaS3(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		threshold int64
+		size      int
+		expect    bool
+	}{
+		{
+			name:   "small package on auto",
+			mode:   CodeUploadAuto,
+			size:   1024,
+			expect: false,
+		},
+		{
+			name:   "package over default threshold",
+			size:   DefaultCodeUploadThreshold*1024*1024 + 1,
+			expect: true,
+		},
+		{
+			name:      "package over configured threshold",
+			threshold: 1,
+			size:      1024*1024 + 1,
+			expect:    true,
+		},
+		{
+			name:   "always upload via S3",
+			mode:   CodeUploadS3,
+			size:   1,
+			expect: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{CodeUpload: tt.mode, CodeUploadThreshold: tt.threshold}
+			if actual := c.UploadCodeViaS3(tt.size); actual != tt.expect {
+				t.Errorf("expected %t, got %t", tt.expect, actual)
+			}
+		})
+	}
+}
+
+func TestArtifactKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		key    string
+		prefix string
+	}{
+		{name: "function", key: FunctionArtifactKey("example", []byte("package")), prefix: FunctionArtifactPrefix},
+		{name: "layer", key: LayerArtifactKey("example", []byte("package")), prefix: LayerArtifactPrefix},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !IsArtifactKey(tt.key) {
+				t.Errorf("expected %s is artifact key", tt.key)
+			}
+			if !strings.HasPrefix(tt.key, tt.prefix+"example/") || !strings.HasSuffix(tt.key, ".zip") {
+				t.Errorf("unexpected artifact key %s", tt.key)
+			}
+		})
+	}
+	if IsArtifactKey("assets/example.zip") {
+		t.Errorf("expected user object isn't artifact key")
+	}
+}
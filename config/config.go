@@ -25,16 +25,26 @@ type Config struct {
 	StoragePath   string `toml:"-"`
 	StagePath     string `toml:"-"`
 	SchedulerPath string `toml:"-"`
+	LayerPath     string `toml:"-"`
+	CachePath     string `toml:"-"`
+	HistoryPath   string `toml:"-"`
 
-	RestApiId         string             `toml:"rest_api_id"`
-	ProjectName       string             `toml:"project_name"`
-	Profile           string             `toml:"profile"`
-	Region            string             `toml:"region"`
-	DefaultLambdaRole string             `toml:"default_lambda_role"`
-	S3BucketName      string             `toml:"s3_bucket_name"`
-	DeployHookCommand string             `toml:"deploy_hook_command"`
-	Resources         []*entity.Resource `toml:"resources"`
-	LocalPackages     []string           `toml:"local_packages"`
+	RestApiId           string               `toml:"rest_api_id"`
+	ProjectName         string               `toml:"project_name"`
+	Profile             string               `toml:"profile"`
+	Region              string               `toml:"region"`
+	DefaultLambdaRole   string               `toml:"default_lambda_role"`
+	S3BucketName        string               `toml:"s3_bucket_name"`
+	DeployHookCommand   string               `toml:"deploy_hook_command"`
+	Resources           []*entity.Resource   `toml:"resources"`
+	Authorizers         []*entity.Authorizer `toml:"authorizers"`
+	ApiKeys             []*entity.ApiKey     `toml:"api_keys"`
+	UsagePlans          []*entity.UsagePlan  `toml:"usage_plans"`
+	LocalPackages       []string             `toml:"local_packages"`
+	GoModFlag           string               `toml:"go_mod_flag"`
+	CodeUpload          string               `toml:"code_upload"`
+	CodeUploadThreshold int64                `toml:"code_upload_threshold"`
+	Cors                *entity.Cors         `toml:"cors"`
 
 	Queue map[string]*entity.Function `toml:"-"`
 	log   *logger.Logger              `toml:"-"`
@@ -52,6 +62,19 @@ func (c *Config) SortResources() {
 	})
 }
 
+// ResolveCors() returns CORS setting for the resource.
+// Resource setting takes precedence over project default, and returns nil if CORS is disabled.
+func (c *Config) ResolveCors(r *entity.Resource) *entity.Cors {
+	cors := c.Cors
+	if r.Cors != nil {
+		cors = r.Cors
+	}
+	if cors == nil || cors.Disabled {
+		return nil
+	}
+	return cors
+}
+
 // Mutex for file I/O
 var mu sync.Mutex
 
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+
+	"github.com/ysugimoto/ginger/entity"
+)
+
+// LoadHistory() loads deployment history of the function.
+// Returns empty history if function has never been deployed.
+func (c *Config) LoadHistory(name string) (*entity.History, error) {
+	h := &entity.History{
+		Deployments: []*entity.Deployment{},
+	}
+	path := filepath.Join(c.HistoryPath, name+".toml")
+	if _, err := os.Stat(path); err != nil {
+		return h, nil
+	}
+	if _, err := toml.DecodeFile(path, h); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode history file")
+	}
+	return h, nil
+}
+
+// WriteHistory() writes deployment history of the function.
+func (c *Config) WriteHistory(name string, h *entity.History) error {
+	if err := os.MkdirAll(c.HistoryPath, 0755); err != nil {
+		return errors.Wrap(err, "Failed to create history directory")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	fp, err := os.OpenFile(filepath.Join(c.HistoryPath, name+".toml"), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return errors.Wrap(err, "Failed to open history file")
+	}
+	defer fp.Close()
+	return toml.NewEncoder(fp).Encode(h)
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"os"
+
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+
+	"github.com/ysugimoto/ginger/entity"
+	"github.com/ysugimoto/ginger/input"
+)
+
+// Directory name in layer directory which is put in zip as it is
+const LayerContentDir = "content"
+
+func (c *Config) LoadLayer(name string) (*entity.Layer, error) {
+	path := filepath.Join(c.LayerPath, name, "Layer.toml")
+	if _, err := os.Stat(path); err != nil {
+		return nil, errors.Wrap(err, "Configuration file does not exist")
+	}
+
+	layer := &entity.Layer{}
+	if _, err := toml.DecodeFile(path, layer); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode configuration file")
+	}
+	return layer, nil
+}
+
+// WriteLayer() writes layer configuration to Layer.toml.
+func (c *Config) WriteLayer(layer *entity.Layer) error {
+	mu.Lock()
+	defer mu.Unlock()
+	fp, err := os.OpenFile(filepath.Join(c.LayerPath, layer.Name, "Layer.toml"), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return errors.Wrap(err, "Failed to open configuration file")
+	}
+	defer fp.Close()
+	return toml.NewEncoder(fp).Encode(layer)
+}
+
+func (c *Config) LoadAllLayers() ([]*entity.Layer, error) {
+	layers := []*entity.Layer{}
+	files, err := ioutil.ReadDir(c.LayerPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return layers, nil
+		}
+		return nil, err
+	}
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		layer, err := c.LoadLayer(f.Name())
+		if err != nil {
+			c.log.Warnf("Skip: couldn't list layer \"%s\": %s\n", f.Name(), err.Error())
+			continue
+		}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+func (c *Config) ChooseLayer() string {
+	layers, _ := c.LoadAllLayers()
+	if len(layers) == 0 {
+		return ""
+	}
+	choose := []string{}
+	for _, layer := range layers {
+		choose = append(choose, layer.Name)
+	}
+	return input.Choice("Select target layer", choose)
+}
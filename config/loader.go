@@ -25,7 +25,13 @@ func Load() *Config {
 		LibPath:       filepath.Join(root, ".ginger"),
 		StagePath:     filepath.Join(root, "stages"),
 		SchedulerPath: filepath.Join(root, "schedulers"),
+		LayerPath:     filepath.Join(root, "layers"),
+		CachePath:     filepath.Join(root, ".ginger", "cache"),
+		HistoryPath:   filepath.Join(root, ".ginger", "history"),
 		Resources:     make([]*entity.Resource, 0),
+		Authorizers:   make([]*entity.Authorizer, 0),
+		ApiKeys:       make([]*entity.ApiKey, 0),
+		UsagePlans:    make([]*entity.UsagePlan, 0),
 		Queue:         make(map[string]*entity.Function, 0),
 		log:           logger.WithNamespace("ginger.config"),
 	}
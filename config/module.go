@@ -0,0 +1,94 @@
+package config
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"io/ioutil"
+	"path/filepath"
+)
+
+// Go module flags which can be set as go_mod_flag
+const (
+	ModFlagReadonly = "readonly"
+	ModFlagVendor   = "vendor"
+	ModFlagMod      = "mod"
+)
+
+// ModuleRoot() returns directory which has go.mod for the function.
+// Function directory's go.mod takes precedence over project root's one,
+// and returns empty string if function isn't managed by Go modules.
+func (c *Config) ModuleRoot(name string) string {
+	return c.ModuleRootOf(filepath.Join(c.FunctionPath, name))
+}
+
+// ModuleRootOf() returns directory which has go.mod for the source directory, e.g. layer directory.
+// Source directory's go.mod takes precedence over project root's one.
+func (c *Config) ModuleRootOf(src string) string {
+	for _, dir := range []string{src, c.Root} {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+	}
+	return ""
+}
+
+// ModuleDirs() returns all directories which have go.mod in the project.
+// Project root comes first if exists, and following are function directories.
+func (c *Config) ModuleDirs() []string {
+	dirs := []string{}
+	if _, err := os.Stat(filepath.Join(c.Root, "go.mod")); err == nil {
+		dirs = append(dirs, c.Root)
+	}
+	files, err := ioutil.ReadDir(c.FunctionPath)
+	if err != nil {
+		return dirs
+	}
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		dir := filepath.Join(c.FunctionPath, f.Name())
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// ModFlag() returns -mod flag value to build the function in module mode.
+// If go_mod_flag isn't configured, use vendor directory if exists, otherwise readonly.
+func (c *Config) ModFlag(name string) string {
+	return c.ModFlagOf(filepath.Join(c.FunctionPath, name))
+}
+
+// ModFlagOf() returns -mod flag value to build the source directory in module mode.
+func (c *Config) ModFlagOf(src string) string {
+	if c.GoModFlag != "" {
+		return c.GoModFlag
+	}
+	root := c.ModuleRootOf(src)
+	if _, err := os.Stat(filepath.Join(root, "vendor", "modules.txt")); err == nil {
+		return ModFlagVendor
+	}
+	return ModFlagReadonly
+}
+
+// ReadModulePath() reads module path from go.mod in the directory.
+// Returns empty string if go.mod doesn't exist or doesn't have module directive.
+func ReadModulePath(dir string) string {
+	fp, err := os.Open(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	defer fp.Close()
+	scanner := bufio.NewScanner(fp)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"io/ioutil"
+	"path/filepath"
+)
+
+func TestModuleRootAndModFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		modFlag string
+		root    string
+		flag    string
+	}{
+		{
+			name: "GOPATH mode",
+			root: "",
+			flag: ModFlagReadonly,
+		},
+		{
+			name:  "project root module",
+			files: []string{"go.mod"},
+			root:  ".",
+			flag:  ModFlagReadonly,
+		},
+		{
+			name:  "function module takes precedence",
+			files: []string{"go.mod", "functions/example/go.mod"},
+			root:  "functions/example",
+			flag:  ModFlagReadonly,
+		},
+		{
+			name:  "vendor directory",
+			files: []string{"go.mod", "vendor/modules.txt"},
+			root:  ".",
+			flag:  ModFlagVendor,
+		},
+		{
+			name:    "configured flag",
+			files:   []string{"go.mod", "vendor/modules.txt"},
+			modFlag: ModFlagMod,
+			root:    ".",
+			flag:    ModFlagMod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := ioutil.TempDir("", "ginger-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(root)
+			for _, file := range append(tt.files, "functions/example/main.go") {
+				path := filepath.Join(root, file)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(path, []byte("module example.com/project\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			c := &Config{Root: root, FunctionPath: filepath.Join(root, "functions"), GoModFlag: tt.modFlag}
+			expect := ""
+			if tt.root != "" {
+				expect = filepath.Join(root, tt.root)
+			}
+			if actual := c.ModuleRoot("example"); actual != expect {
+				t.Errorf("expected module root %s, got %s", expect, actual)
+			}
+			if actual := c.ModFlag("example"); actual != tt.flag {
+				t.Errorf("expected mod flag %s, got %s", tt.flag, actual)
+			}
+		})
+	}
+}
+
+func TestReadModulePath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ginger-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if actual := ReadModulePath(dir); actual != "" {
+		t.Errorf("expected empty module path without go.mod, got %s", actual)
+	}
+	body := "// comment\nmodule \"example.com/project\"\n\ngo 1.18\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if actual := ReadModulePath(dir); actual != "example.com/project" {
+		t.Errorf("expected example.com/project, got %s", actual)
+	}
+}
+
+func TestModuleRootOf(t *testing.T) {
+	root, err := ioutil.TempDir("", "ginger-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	layer := filepath.Join(root, "layers", "tools")
+	if err := os.MkdirAll(filepath.Join(layer, "vendor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Config{Root: root, FunctionPath: filepath.Join(root, "functions")}
+	if actual := c.ModuleRootOf(layer); actual != "" {
+		t.Errorf("expected empty module root in GOPATH mode, got %s", actual)
+	}
+	for _, file := range []string{"go.mod", "vendor/modules.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(layer, file), []byte("module example.com/tools\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if actual := c.ModuleRootOf(layer); actual != layer {
+		t.Errorf("expected module root %s, got %s", layer, actual)
+	}
+	if actual := c.ModFlagOf(layer); actual != ModFlagVendor {
+		t.Errorf("expected mod flag %s, got %s", ModFlagVendor, actual)
+	}
+}
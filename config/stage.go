@@ -26,7 +26,7 @@ func (c *Config) LoadStage(name string) (*entity.Stage, error) {
 }
 
 func (c *Config) DeleteStage(name string) error {
-	path := filepath.Join(c.StagePath, name, fmt.Sprintf("%s.toml"))
+	path := filepath.Join(c.StagePath, fmt.Sprintf("%s.toml", name))
 	if _, err := os.Stat(path); err != nil {
 		return errors.Wrap(err, "Stage configuration file does not exist")
 	}
@@ -47,7 +47,8 @@ func (c *Config) LoadAllStages() ([]*entity.Stage, error) {
 		} else if filepath.Ext(info.Name()) != ".toml" {
 			return nil
 		}
-		stg, err := c.LoadStage(filepath.Base(info.Name()))
+		name := info.Name()
+		stg, err := c.LoadStage(name[0 : len(name)-5])
 		if err != nil {
 			fmt.Printf("Skip: couldn't list stage \"%s\": %s\n", info.Name(), err.Error())
 			return nil
@@ -0,0 +1,9 @@
+package entity
+
+// ApiKey is the entity struct which maps 'api_keys' slice in configuration.
+// Note that key value is not stored in configuration, it is generated by API Gateway.
+type ApiKey struct {
+	Id      string `toml:"id"`
+	Name    string `toml:"name"`
+	Enabled bool   `toml:"enabled"`
+}
@@ -0,0 +1,53 @@
+package entity
+
+// Authorizer types
+const (
+	AuthorizerToken   = "TOKEN"
+	AuthorizerRequest = "REQUEST"
+	AuthorizerCognito = "COGNITO_USER_POOLS"
+)
+
+// Authorizer is the entity struct which maps 'authorizers' slice in configuration.
+// Authorizer type accepts on "TOKEN", "REQUEST" or "COGNITO_USER_POOLS":
+//
+//	If Type is "TOKEN" or "REQUEST", Function must be ginger function name.
+//	If Type is "COGNITO_USER_POOLS", ProviderArns must not be empty.
+type Authorizer struct {
+	Id             string   `toml:"id"`
+	Name           string   `toml:"name"`
+	Type           string   `toml:"type"`
+	Function       string   `toml:"function"`
+	ProviderArns   []string `toml:"provider_arns"`
+	IdentitySource string   `toml:"identity_source"`
+	TTL            int64    `toml:"ttl"`
+}
+
+func NewAuthorizer(name, aType string) *Authorizer {
+	return &Authorizer{
+		Name:           name,
+		Type:           aType,
+		ProviderArns:   make([]string, 0),
+		IdentitySource: "method.request.header.Authorization",
+		TTL:            300,
+	}
+}
+
+// IsLambda() returns true if authorizer is backed by lambda function.
+func (a *Authorizer) IsLambda() bool {
+	return a.Type == AuthorizerToken || a.Type == AuthorizerRequest
+}
+
+// AuthorizationType() returns method authorization type which uses this authorizer.
+func (a *Authorizer) AuthorizationType() string {
+	if a.Type == AuthorizerCognito {
+		return AuthorizerCognito
+	}
+	return "CUSTOM"
+}
+
+func (a *Authorizer) String() string {
+	if a.IsLambda() {
+		return a.Type + ":" + a.Function
+	}
+	return a.Type
+}
@@ -0,0 +1,118 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Default allowed headers which API Gateway console also uses.
+var DefaultCorsHeaders = []string{
+	"Content-Type",
+	"X-Amz-Date",
+	"Authorization",
+	"X-Api-Key",
+	"X-Amz-Security-Token",
+}
+
+// HTTP methods which "ANY" integration accepts
+var anyMethods = []string{"DELETE", "GET", "HEAD", "PATCH", "POST", "PUT"}
+
+// Cors is the struct which maps 'cors' field of resource or project.
+// If AllowMethods is empty, the methods of mounted integrations are allowed.
+// Set Disabled to true for a resource to opt out of project default.
+type Cors struct {
+	Disabled         bool     `toml:"disabled"`
+	AllowOrigins     []string `toml:"allow_origins"`
+	AllowMethods     []string `toml:"allow_methods"`
+	AllowHeaders     []string `toml:"allow_headers"`
+	MaxAge           int64    `toml:"max_age"`
+	AllowCredentials bool     `toml:"allow_credentials"`
+}
+
+// Origin() returns the default value of Access-Control-Allow-Origin header.
+// Note that the header accepts only one origin, so other origins are resolved on request.
+func (c *Cors) Origin() string {
+	if len(c.AllowOrigins) == 0 {
+		return "*"
+	}
+	return c.AllowOrigins[0]
+}
+
+// Methods() returns the value of Access-Control-Allow-Methods header.
+// "ANY" isn't HTTP method, so it's expanded to concrete methods.
+func (c *Cors) Methods(integrations map[string]*Integration) string {
+	methods := c.AllowMethods
+	if len(methods) == 0 {
+		methods = []string{}
+		for method := range integrations {
+			methods = append(methods, method)
+		}
+	}
+	unique := map[string]struct{}{"OPTIONS": {}}
+	for _, m := range methods {
+		if m = strings.ToUpper(m); m == "ANY" {
+			for _, am := range anyMethods {
+				unique[am] = struct{}{}
+			}
+		} else {
+			unique[m] = struct{}{}
+		}
+	}
+	methods = []string{}
+	for m := range unique {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ",")
+}
+
+// Headers() returns the value of Access-Control-Allow-Headers header.
+func (c *Cors) Headers() string {
+	if len(c.AllowHeaders) == 0 {
+		return strings.Join(DefaultCorsHeaders, ",")
+	}
+	return strings.Join(c.AllowHeaders, ",")
+}
+
+// ResponseHeaders() returns static Access-Control-* headers which should be responded.
+func (c *Cors) ResponseHeaders(integrations map[string]*Integration) map[string]string {
+	headers := map[string]string{
+		"Access-Control-Allow-Origin":  c.Origin(),
+		"Access-Control-Allow-Methods": c.Methods(integrations),
+		"Access-Control-Allow-Headers": c.Headers(),
+	}
+	if c.MaxAge > 0 {
+		headers["Access-Control-Max-Age"] = fmt.Sprint(c.MaxAge)
+	}
+	if c.AllowCredentials {
+		headers["Access-Control-Allow-Credentials"] = "true"
+	}
+	return headers
+}
+
+// Validate() returns error if CORS setting is invalid.
+// Browsers reject credentialed request if Access-Control-Allow-Origin is wildcard.
+func (c *Cors) Validate() error {
+	if !c.AllowCredentials {
+		return nil
+	}
+	if len(c.AllowOrigins) == 0 {
+		return errors.New("allow_credentials requires explicit allow_origins")
+	}
+	for _, origin := range c.AllowOrigins {
+		if origin == "*" {
+			return errors.New("allow_credentials cannot be used with \"*\" origin")
+		}
+	}
+	return nil
+}
+
+func (c *Cors) String() string {
+	origins := c.AllowOrigins
+	if len(origins) == 0 {
+		origins = []string{"*"}
+	}
+	return fmt.Sprintf("origins=%s credentials=%t", strings.Join(origins, ","), c.AllowCredentials)
+}
@@ -0,0 +1,113 @@
+package entity
+
+import (
+	"testing"
+)
+
+func TestCorsMethods(t *testing.T) {
+	tests := []struct {
+		name         string
+		allowMethods []string
+		integrations []string
+		expect       string
+	}{
+		{
+			name:         "integration methods",
+			integrations: []string{"POST", "GET"},
+			expect:       "GET,OPTIONS,POST",
+		},
+		{
+			name:         "ANY integration is expanded",
+			integrations: []string{"ANY"},
+			expect:       "DELETE,GET,HEAD,OPTIONS,PATCH,POST,PUT",
+		},
+		{
+			name:         "ANY with other methods is not duplicated",
+			integrations: []string{"ANY", "GET"},
+			expect:       "DELETE,GET,HEAD,OPTIONS,PATCH,POST,PUT",
+		},
+		{
+			name:         "allow_methods takes precedence",
+			allowMethods: []string{"get", "put"},
+			integrations: []string{"ANY"},
+			expect:       "GET,OPTIONS,PUT",
+		},
+		{
+			name:         "OPTIONS is not duplicated",
+			allowMethods: []string{"GET", "OPTIONS"},
+			expect:       "GET,OPTIONS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			integrations := map[string]*Integration{}
+			for _, m := range tt.integrations {
+				integrations[m] = &Integration{}
+			}
+			c := &Cors{AllowMethods: tt.allowMethods}
+			if actual := c.Methods(integrations); actual != tt.expect {
+				t.Errorf("expected %s, got %s", tt.expect, actual)
+			}
+		})
+	}
+}
+
+func TestCorsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cors    *Cors
+		isError bool
+	}{
+		{
+			name: "wildcard origin without credentials",
+			cors: &Cors{},
+		},
+		{
+			name: "explicit origin with credentials",
+			cors: &Cors{AllowOrigins: []string{"https://example.com"}, AllowCredentials: true},
+		},
+		{
+			name:    "default wildcard origin with credentials",
+			cors:    &Cors{AllowCredentials: true},
+			isError: true,
+		},
+		{
+			name:    "wildcard origin with credentials",
+			cors:    &Cors{AllowOrigins: []string{"https://example.com", "*"}, AllowCredentials: true},
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cors.Validate()
+			if tt.isError && err == nil {
+				t.Errorf("expected error, got nil")
+			} else if !tt.isError && err != nil {
+				t.Errorf("unexpected error: %s", err.Error())
+			}
+		})
+	}
+}
+
+func TestCorsResponseHeaders(t *testing.T) {
+	c := &Cors{
+		AllowOrigins:     []string{"https://example.com"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	}
+	headers := c.ResponseHeaders(map[string]*Integration{"GET": &Integration{}})
+	expects := map[string]string{
+		"Access-Control-Allow-Origin":      "https://example.com",
+		"Access-Control-Allow-Methods":     "GET,OPTIONS",
+		"Access-Control-Allow-Headers":     c.Headers(),
+		"Access-Control-Max-Age":           "600",
+		"Access-Control-Allow-Credentials": "true",
+	}
+	for name, expect := range expects {
+		if headers[name] != expect {
+			t.Errorf("expected %s header %s, got %s", name, expect, headers[name])
+		}
+	}
+}
@@ -1,18 +1,349 @@
 package entity
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Supported Lambda runtimes
+const (
+	RuntimeGo1x           = "go1.x"
+	RuntimeProvidedAl2    = "provided.al2"
+	RuntimeProvidedAl2023 = "provided.al2023"
+)
+
+// Supported Lambda architectures
+const (
+	ArchitectureX86_64 = "x86_64"
+	ArchitectureArm64  = "arm64"
+)
+
+// Traffic shifting strategies on deployment
+const (
+	StrategyAllAtOnce = "all-at-once"
+	StrategyCanary    = "canary"
+	StrategyLinear    = "linear"
+)
+
+// Default traffic shifting parameters
+const (
+	defaultShiftPercentage = 10
+	defaultShiftInterval   = 5
+)
+
+// Executable name which custom runtime runs
+const customRuntimeHandler = "bootstrap"
+
+// FunctionBuild is the build configuration of function which maps [build] section.
+type FunctionBuild struct {
+	Tags       []string `toml:"tags"`
+	Ldflags    string   `toml:"ldflags"`
+	CgoEnabled *bool    `toml:"cgo_enabled"`
+	Main       string   `toml:"main"`
+	Files      []string `toml:"files"`
+}
+
+// FunctionDeployment is the traffic shifting configuration which maps [deployment] section.
+// Traffic of Alias is shifted to new version by Percentage in each Interval minutes,
+// and rolled back if errors or throttles exceed thresholds, or either of Alarms is in ALARM state.
+type FunctionDeployment struct {
+	Strategy          string   `toml:"strategy"`
+	Alias             string   `toml:"alias"`
+	Percentage        float64  `toml:"percentage"`
+	Interval          int64    `toml:"interval"`
+	ErrorThreshold    float64  `toml:"error_threshold"`
+	ThrottleThreshold float64  `toml:"throttle_threshold"`
+	Alarms            []string `toml:"alarms"`
+}
+
+// GetPercentage() returns traffic percentage per step, default is 10%.
+func (d *FunctionDeployment) GetPercentage() float64 {
+	if d.Percentage <= 0 || d.Percentage > 100 {
+		return defaultShiftPercentage
+	}
+	return d.Percentage
+}
+
+// GetInterval() returns minutes to bake each step, default is 5 minutes.
+func (d *FunctionDeployment) GetInterval() int64 {
+	if d.Interval <= 0 {
+		return defaultShiftInterval
+	}
+	return d.Interval
+}
+
+// Steps() returns traffic percentages of new version in order.
+// Canary shifts Percentage at first, then all traffic.
+// Linear increases Percentage on each step until all traffic.
+func (d *FunctionDeployment) Steps(strategy string) []float64 {
+	steps := []float64{}
+	switch strategy {
+	case StrategyCanary:
+		steps = append(steps, d.GetPercentage())
+	case StrategyLinear:
+		for p := d.GetPercentage(); p < 100; p += d.GetPercentage() {
+			steps = append(steps, p)
+		}
+	}
+	if len(steps) > 0 && steps[len(steps)-1] >= 100 {
+		steps = steps[0 : len(steps)-1]
+	}
+	return append(steps, 100)
+}
+
+// FunctionAsync is the asynchronous invocation configuration which maps [async] section.
+// DeadLetterQueue, OnSuccess and OnFailure accept SQS queue or SNS topic ARN,
+// and destinations also accept Lambda function or EventBridge event bus ARN.
+type FunctionAsync struct {
+	DeadLetterQueue      string `toml:"dead_letter_queue"`
+	MaximumRetryAttempts *int64 `toml:"maximum_retry_attempts"`
+	MaximumEventAge      *int64 `toml:"maximum_event_age"`
+	OnSuccess            string `toml:"on_success"`
+	OnFailure            string `toml:"on_failure"`
+}
+
+// HasInvokeConfig() returns true if either of retry, event age or destinations is configured.
+func (a *FunctionAsync) HasInvokeConfig() bool {
+	return a.MaximumRetryAttempts != nil || a.MaximumEventAge != nil || a.OnSuccess != "" || a.OnFailure != ""
+}
+
+// FailureQueue() returns SQS queue ARN which failed events are sent to.
+// Dead letter queue takes precedence over on-failure destination, and returns empty if both are not SQS.
+func (a *FunctionAsync) FailureQueue() string {
+	for _, arn := range []string{a.DeadLetterQueue, a.OnFailure} {
+		if strings.HasPrefix(arn, "arn:aws:sqs:") {
+			return arn
+		}
+	}
+	return ""
+}
+
+// FunctionAlias is the alias of function which maps [[aliases]] section.
+// If Version is empty, alias follows the version which is published on each deployment.
+// ProvisionedConcurrency is allocated for the alias, and zero means no provisioned concurrency.
+type FunctionAlias struct {
+	Name                   string `toml:"name"`
+	Version                string `toml:"version"`
+	ProvisionedConcurrency int64  `toml:"provisioned_concurrency"`
+}
+
 type VPC struct {
 	Subnets        []string `toml:"subnets"`
 	SecurityGroups []string `toml:"security_groups"`
 }
 
 // Function is the entity struct which maps from configuration.
+// ReservedConcurrency caps concurrent executions of the function, and nil means unreserved.
+// Layers accepts layer name which is resolved to the latest published version, or layer version ARN.
 type Function struct {
-	Name        string             `toml:"name"`
-	Arn         string             `toml:"arn"`
-	MemorySize  int64              `toml:"memory_size"`
-	Timeout     int64              `toml:"timeout"`
-	Role        string             `toml:"role"`
-	Schedule    *string            `toml:"schedule"`
-	VPC         *VPC               `toml:"vpc"`
-	Environment map[string]*string `toml:"environment"`
+	Name                string              `toml:"name"`
+	Arn                 string              `toml:"arn"`
+	Runtime             string              `toml:"runtime"`
+	Architecture        string              `toml:"architecture"`
+	MemorySize          int64               `toml:"memory_size"`
+	Timeout             int64               `toml:"timeout"`
+	Role                string              `toml:"role"`
+	ReservedConcurrency *int64              `toml:"reserved_concurrency"`
+	Schedule            *string             `toml:"schedule"`
+	VPC                 *VPC                `toml:"vpc"`
+	Environment         map[string]*string  `toml:"environment"`
+	Layers              []string            `toml:"layers"`
+	Build               *FunctionBuild      `toml:"build"`
+	Version             string              `toml:"version"`
+	Aliases             []*FunctionAlias    `toml:"aliases"`
+	Deployment          *FunctionDeployment `toml:"deployment"`
+	Async               *FunctionAsync      `toml:"async"`
+}
+
+// SplitQualifier() splits function reference like "name:alias" into function name and qualifier.
+// Qualifier is empty if reference points unqualified function.
+func SplitQualifier(ref string) (string, string) {
+	if index := strings.Index(ref, ":"); index != -1 {
+		return ref[0:index], ref[index+1:]
+	}
+	return ref, ""
+}
+
+// GetRuntime() returns Lambda runtime.
+// Function which doesn't have runtime field has been created as go1.x.
+func (f *Function) GetRuntime() string {
+	if f.Runtime == "" {
+		return RuntimeGo1x
+	}
+	return f.Runtime
+}
+
+// GetArchitecture() returns Lambda architecture, default is x86_64.
+func (f *Function) GetArchitecture() string {
+	if f.Architecture == "" {
+		return ArchitectureX86_64
+	}
+	return f.Architecture
+}
+
+// IsCustomRuntime() returns true if function runs on provided.* runtime.
+func (f *Function) IsCustomRuntime() bool {
+	return strings.HasPrefix(f.GetRuntime(), "provided")
+}
+
+// Handler() returns executable name in the zip archive.
+// Custom runtime always runs "bootstrap", and go1.x runs the binary which is named by function name.
+func (f *Function) Handler() string {
+	if f.IsCustomRuntime() {
+		return customRuntimeHandler
+	}
+	return f.Name
+}
+
+// GoArch() returns GOARCH value to build binary.
+func (f *Function) GoArch() string {
+	if f.GetArchitecture() == ArchitectureArm64 {
+		return "arm64"
+	}
+	return "amd64"
+}
+
+// MainPackage() returns package path to build, relative from function directory.
+func (f *Function) MainPackage() string {
+	if f.Build == nil || f.Build.Main == "" {
+		return "."
+	}
+	return f.Build.Main
+}
+
+// BuildTags() returns build tags which are configured in [build] section.
+func (f *Function) BuildTags() []string {
+	if f.Build == nil {
+		return []string{}
+	}
+	return f.Build.Tags
+}
+
+// ValidateRuntime() returns error if runtime and architecture combination is not supported.
+func (f *Function) ValidateRuntime() error {
+	switch f.GetRuntime() {
+	case RuntimeGo1x, RuntimeProvidedAl2, RuntimeProvidedAl2023:
+	default:
+		return errors.New("unsupported runtime " + f.GetRuntime())
+	}
+	switch f.GetArchitecture() {
+	case ArchitectureX86_64, ArchitectureArm64:
+	default:
+		return errors.New("unsupported architecture " + f.GetArchitecture())
+	}
+	if f.GetRuntime() == RuntimeGo1x && f.GetArchitecture() != ArchitectureX86_64 {
+		return errors.New("go1.x runtime supports only x86_64 architecture")
+	}
+	return nil
+}
+
+// GetAlias() returns declared alias, or nil if not found.
+func (f *Function) GetAlias(name string) *FunctionAlias {
+	for _, a := range f.Aliases {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// SetAlias() sets version to alias, and declares alias if not exists.
+func (f *Function) SetAlias(name, version string) {
+	if a := f.GetAlias(name); a != nil {
+		a.Version = version
+		return
+	}
+	f.Aliases = append(f.Aliases, &FunctionAlias{
+		Name:    name,
+		Version: version,
+	})
+}
+
+// DeploymentStrategy() returns traffic shifting strategy.
+// Supplied strategy takes precedence over [deployment] section, and default is all-at-once.
+func (f *Function) DeploymentStrategy(strategy string) string {
+	if strategy != "" {
+		return strategy
+	} else if f.Deployment != nil && f.Deployment.Strategy != "" {
+		return f.Deployment.Strategy
+	}
+	return StrategyAllAtOnce
+}
+
+// ValidateDeployment() returns error if traffic shifting can't be run by the strategy.
+func (f *Function) ValidateDeployment(strategy string) error {
+	switch strategy {
+	case StrategyAllAtOnce:
+		return nil
+	case StrategyCanary, StrategyLinear:
+	default:
+		return errors.New("unsupported strategy " + strategy)
+	}
+	if f.Deployment == nil || f.Deployment.Alias == "" {
+		return errors.New("alias to shift traffic is required in [deployment] section")
+	} else if a := f.GetAlias(f.Deployment.Alias); a == nil {
+		return errors.New("alias " + f.Deployment.Alias + " isn't declared")
+	}
+	return nil
+}
+
+// DeadLetterQueue() returns dead letter queue ARN, or empty if not configured.
+func (f *Function) DeadLetterQueue() string {
+	if f.Async == nil {
+		return ""
+	}
+	return f.Async.DeadLetterQueue
+}
+
+// ValidateAsync() returns error if asynchronous invocation configuration is invalid.
+func (f *Function) ValidateAsync() error {
+	if f.Async == nil {
+		return nil
+	}
+	if dlq := f.Async.DeadLetterQueue; dlq != "" && !strings.HasPrefix(dlq, "arn:aws:sqs:") && !strings.HasPrefix(dlq, "arn:aws:sns:") {
+		return errors.New("dead_letter_queue must be SQS queue or SNS topic ARN")
+	}
+	if n := f.Async.MaximumRetryAttempts; n != nil && (*n < 0 || *n > 2) {
+		return errors.New("maximum_retry_attempts must be between 0 and 2")
+	}
+	if n := f.Async.MaximumEventAge; n != nil && (*n < 60 || *n > 21600) {
+		return errors.New("maximum_event_age must be between 60 and 21600 seconds")
+	}
+	return nil
+}
+
+// AWS Lambda can attach up to 5 layers to a function
+const MaxFunctionLayers = 5
+
+// ValidateLayers() returns error if layer references are invalid.
+func (f *Function) ValidateLayers() error {
+	if len(f.Layers) > MaxFunctionLayers {
+		return fmt.Errorf("function can attach up to %d layers", MaxFunctionLayers)
+	}
+	for _, layer := range f.Layers {
+		if layer == "" {
+			return errors.New("layer name must not be empty")
+		}
+	}
+	return nil
+}
+
+// ValidateConcurrency() returns error if concurrency settings are invalid.
+// Provisioned concurrency is allocated from reserved concurrency, so the total of aliases must not exceed it.
+func (f *Function) ValidateConcurrency() error {
+	if f.ReservedConcurrency != nil && *f.ReservedConcurrency < 0 {
+		return errors.New("reserved_concurrency must not be negative")
+	}
+	var total int64
+	for _, a := range f.Aliases {
+		if a.ProvisionedConcurrency < 0 {
+			return errors.New("provisioned_concurrency of alias " + a.Name + " must not be negative")
+		}
+		total += a.ProvisionedConcurrency
+	}
+	if f.ReservedConcurrency != nil && total > *f.ReservedConcurrency {
+		return fmt.Errorf("total provisioned_concurrency %d exceeds reserved_concurrency %d", total, *f.ReservedConcurrency)
+	}
+	return nil
 }
@@ -0,0 +1,205 @@
+package entity
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestFunctionRuntime(t *testing.T) {
+	tests := []struct {
+		name         string
+		runtime      string
+		architecture string
+		handler      string
+		goarch       string
+		isError      bool
+	}{
+		{
+			name:    "go1.x is default",
+			handler: "example",
+			goarch:  "amd64",
+		},
+		{
+			name:    "custom runtime runs bootstrap",
+			runtime: RuntimeProvidedAl2023,
+			handler: "bootstrap",
+			goarch:  "amd64",
+		},
+		{
+			name:         "arm64 on custom runtime",
+			runtime:      RuntimeProvidedAl2,
+			architecture: ArchitectureArm64,
+			handler:      "bootstrap",
+			goarch:       "arm64",
+		},
+		{
+			name:         "arm64 on go1.x",
+			runtime:      RuntimeGo1x,
+			architecture: ArchitectureArm64,
+			handler:      "example",
+			goarch:       "arm64",
+			isError:      true,
+		},
+		{
+			name:    "unsupported runtime",
+			runtime: "nodejs18.x",
+			handler: "example",
+			goarch:  "amd64",
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := &Function{Name: "example", Runtime: tt.runtime, Architecture: tt.architecture}
+			if actual := fn.Handler(); actual != tt.handler {
+				t.Errorf("expected handler %s, got %s", tt.handler, actual)
+			}
+			if actual := fn.GoArch(); actual != tt.goarch {
+				t.Errorf("expected GOARCH %s, got %s", tt.goarch, actual)
+			}
+			if err := fn.ValidateRuntime(); (err != nil) != tt.isError {
+				t.Errorf("expected error %t, got %v", tt.isError, err)
+			}
+		})
+	}
+}
+
+func TestSplitQualifier(t *testing.T) {
+	tests := []struct {
+		ref       string
+		name      string
+		qualifier string
+	}{
+		{ref: "example", name: "example"},
+		{ref: "example:live", name: "example", qualifier: "live"},
+		{ref: "example:3", name: "example", qualifier: "3"},
+		{ref: "example:", name: "example"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			name, qualifier := SplitQualifier(tt.ref)
+			if name != tt.name || qualifier != tt.qualifier {
+				t.Errorf("expected (%s, %s), got (%s, %s)", tt.name, tt.qualifier, name, qualifier)
+			}
+		})
+	}
+}
+
+func TestFunctionDeploymentSteps(t *testing.T) {
+	tests := []struct {
+		name       string
+		strategy   string
+		percentage float64
+		expect     []float64
+	}{
+		{
+			name:     "all at once",
+			strategy: StrategyAllAtOnce,
+			expect:   []float64{100},
+		},
+		{
+			name:       "canary",
+			strategy:   StrategyCanary,
+			percentage: 20,
+			expect:     []float64{20, 100},
+		},
+		{
+			name:     "canary with default percentage",
+			strategy: StrategyCanary,
+			expect:   []float64{10, 100},
+		},
+		{
+			name:       "linear",
+			strategy:   StrategyLinear,
+			percentage: 25,
+			expect:     []float64{25, 50, 75, 100},
+		},
+		{
+			name:       "linear which doesn't divide 100",
+			strategy:   StrategyLinear,
+			percentage: 30,
+			expect:     []float64{30, 60, 90, 100},
+		},
+		{
+			name:       "canary with 100 percentage",
+			strategy:   StrategyCanary,
+			percentage: 100,
+			expect:     []float64{100},
+		},
+		{
+			name:       "linear with 100 percentage",
+			strategy:   StrategyLinear,
+			percentage: 100,
+			expect:     []float64{100},
+		},
+		{
+			name:       "percentage over 100 falls back to default",
+			strategy:   StrategyCanary,
+			percentage: 150,
+			expect:     []float64{10, 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &FunctionDeployment{Percentage: tt.percentage}
+			if actual := d.Steps(tt.strategy); !reflect.DeepEqual(actual, tt.expect) {
+				t.Errorf("expected %v, got %v", tt.expect, actual)
+			}
+		})
+	}
+}
+
+func TestFunctionValidateConcurrency(t *testing.T) {
+	reserved := func(v int64) *int64 { return &v }
+	tests := []struct {
+		name     string
+		reserved *int64
+		aliases  []int64
+		isError  bool
+	}{
+		{
+			name:    "unreserved",
+			aliases: []int64{100, 200},
+		},
+		{
+			name:     "within reserved concurrency",
+			reserved: reserved(30),
+			aliases:  []int64{10, 20},
+		},
+		{
+			name:     "exceeds reserved concurrency",
+			reserved: reserved(20),
+			aliases:  []int64{10, 20},
+			isError:  true,
+		},
+		{
+			name:     "negative reserved concurrency",
+			reserved: reserved(-1),
+			isError:  true,
+		},
+		{
+			name:    "negative provisioned concurrency",
+			aliases: []int64{-1},
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := &Function{ReservedConcurrency: tt.reserved}
+			for i, c := range tt.aliases {
+				fn.Aliases = append(fn.Aliases, &FunctionAlias{
+					Name:                   fmt.Sprintf("alias%d", i),
+					ProvisionedConcurrency: c,
+				})
+			}
+			if err := fn.ValidateConcurrency(); (err != nil) != tt.isError {
+				t.Errorf("expected error %t, got %v", tt.isError, err)
+			}
+		})
+	}
+}
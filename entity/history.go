@@ -0,0 +1,71 @@
+package entity
+
+import (
+	"time"
+)
+
+// Deployment is the entry of function deployment history.
+// If package was uploaded via S3, artifact location is recorded to deploy it again.
+type Deployment struct {
+	Version         string    `toml:"version"`
+	CodeSha256      string    `toml:"code_sha256"`
+	GitCommit       string    `toml:"git_commit"`
+	DeployedAt      time.Time `toml:"deployed_at"`
+	S3Bucket        string    `toml:"s3_bucket"`
+	S3Key           string    `toml:"s3_key"`
+	S3ObjectVersion string    `toml:"s3_object_version"`
+	RollbackOf      string    `toml:"rollback_of"`
+	Config          *Function `toml:"config"`
+}
+
+// HasArtifact() returns true if deployed package is kept in S3.
+func (d *Deployment) HasArtifact() bool {
+	return d.S3Bucket != "" && d.S3Key != ""
+}
+
+// History is the deployment history of function, older deployment comes first.
+type History struct {
+	Deployments []*Deployment `toml:"deployments"`
+}
+
+// Add() appends deployment to history.
+// Same version is published when code and configuration aren't changed, then the entry is replaced.
+func (h *History) Add(d *Deployment) {
+	for i, v := range h.Deployments {
+		if v.Version == d.Version {
+			h.Deployments = append(h.Deployments[0:i], h.Deployments[i+1:]...)
+			break
+		}
+	}
+	h.Deployments = append(h.Deployments, d)
+}
+
+// Find() returns deployment of the version, or nil if not found.
+func (h *History) Find(version string) *Deployment {
+	for _, d := range h.Deployments {
+		if d.Version == version {
+			return d
+		}
+	}
+	return nil
+}
+
+// FindArtifact() returns the latest deployment which has S3 artifact of the code, or nil if not found.
+func (h *History) FindArtifact(codeSha256 string) *Deployment {
+	for i := len(h.Deployments) - 1; i >= 0; i-- {
+		if d := h.Deployments[i]; d.CodeSha256 == codeSha256 && d.HasArtifact() {
+			return d
+		}
+	}
+	return nil
+}
+
+// Previous() returns the latest deployment before the version, or nil if not found.
+func (h *History) Previous(version string) *Deployment {
+	for i := len(h.Deployments) - 1; i >= 0; i-- {
+		if h.Deployments[i].Version == version && i > 0 {
+			return h.Deployments[i-1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	h := &History{}
+	h.Add(&Deployment{Version: "1", CodeSha256: "a", S3Bucket: "bucket", S3Key: "1.zip"})
+	h.Add(&Deployment{Version: "2", CodeSha256: "b"})
+	h.Add(&Deployment{Version: "3", CodeSha256: "a"})
+	// Same version is replaced and moved to the latest
+	h.Add(&Deployment{Version: "2", CodeSha256: "b", S3Bucket: "bucket", S3Key: "2.zip"})
+
+	versions := ""
+	for _, d := range h.Deployments {
+		versions += d.Version
+	}
+	if versions != "132" {
+		t.Errorf("expected deployments 132, got %s", versions)
+	}
+
+	tests := []struct {
+		name   string
+		find   func() *Deployment
+		expect string
+	}{
+		{
+			name:   "find version",
+			find:   func() *Deployment { return h.Find("3") },
+			expect: "3",
+		},
+		{
+			name: "find unknown version",
+			find: func() *Deployment { return h.Find("4") },
+		},
+		{
+			name:   "previous deployment",
+			find:   func() *Deployment { return h.Previous("2") },
+			expect: "3",
+		},
+		{
+			name: "previous of the first deployment",
+			find: func() *Deployment { return h.Previous("1") },
+		},
+		{
+			name:   "artifact of the code",
+			find:   func() *Deployment { return h.FindArtifact("a") },
+			expect: "1",
+		},
+		{
+			name: "code without artifact",
+			find: func() *Deployment { return h.FindArtifact("c") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.find()
+			if tt.expect == "" {
+				if d != nil {
+					t.Errorf("expected nil, got version %s", d.Version)
+				}
+			} else if d == nil || d.Version != tt.expect {
+				t.Errorf("expected version %s, got %v", tt.expect, d)
+			}
+		})
+	}
+}
@@ -2,8 +2,9 @@ package entity
 
 // Integration is the struct which maps api.resources.integration field.
 // Integration type accepts on "lambda" or "s3":
-//   If IntegrationType is "s3", Bucket field must not be empty.
-//   If IntegrationType is "lambda", LambdaFunction must not be empty.
+//
+//	If IntegrationType is "s3", Bucket field must not be empty.
+//	If IntegrationType is "lambda", LambdaFunction must not be empty.
 type Integration struct {
 	Id              string  `toml:"resource_id"`
 	IntegrationType string  `toml:"type"`
@@ -11,6 +12,8 @@ type Integration struct {
 	Path            string  `toml:"path"`
 	BucketPath      *string `toml:"bucket_path"`
 	ProxyResourceId *string `toml:"proxy_resource_id"`
+	Authorizer      *string `toml:"authorizer"`
+	ApiKeyRequired  bool    `toml:"api_key_required"`
 }
 
 func NewIntegration(iType, value, path string) *Integration {
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"errors"
+)
+
+// LayerBinary is the Go binary which is built into layer's bin directory.
+// Main is main package path relative from layer directory.
+type LayerBinary struct {
+	Name string `toml:"name"`
+	Main string `toml:"main"`
+}
+
+// Layer is the entity struct which maps from layer configuration.
+// Arn, Version and CodeSha256 are the latest published layer version.
+type Layer struct {
+	Name                    string         `toml:"name"`
+	Description             string         `toml:"description"`
+	Arn                     string         `toml:"arn"`
+	Version                 int64          `toml:"version"`
+	CodeSha256              string         `toml:"code_sha256"`
+	CompatibleRuntimes      []string       `toml:"compatible_runtimes"`
+	CompatibleArchitectures []string       `toml:"compatible_architectures"`
+	Binaries                []*LayerBinary `toml:"binaries"`
+}
+
+// GoArch() returns GOARCH value to build binaries.
+// Layer which has binaries supports exactly one architecture.
+func (l *Layer) GoArch() string {
+	if len(l.CompatibleArchitectures) > 0 && l.CompatibleArchitectures[0] == ArchitectureArm64 {
+		return "arm64"
+	}
+	return "amd64"
+}
+
+// Validate() returns error if layer configuration is invalid.
+func (l *Layer) Validate() error {
+	for _, arch := range l.CompatibleArchitectures {
+		switch arch {
+		case ArchitectureX86_64, ArchitectureArm64:
+		default:
+			return errors.New("unsupported architecture " + arch)
+		}
+	}
+	// Binaries are built for one architecture, so layer must not be attached to functions of other one
+	if len(l.Binaries) > 0 && len(l.CompatibleArchitectures) != 1 {
+		return errors.New("layer which has binaries must have exactly one compatible architecture")
+	}
+	for _, b := range l.Binaries {
+		if b.Name == "" || b.Main == "" {
+			return errors.New("binary must have both of name and main")
+		}
+	}
+	return nil
+}
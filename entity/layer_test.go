@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"testing"
+)
+
+func TestLayerValidate(t *testing.T) {
+	binaries := []*LayerBinary{{Name: "migrate", Main: "./cmd/migrate"}}
+	tests := []struct {
+		name    string
+		layer   *Layer
+		isError bool
+	}{
+		{
+			name:  "content only layer supports any architecture",
+			layer: &Layer{Name: "content"},
+		},
+		{
+			name:  "binaries with one architecture",
+			layer: &Layer{Name: "tools", CompatibleArchitectures: []string{ArchitectureArm64}, Binaries: binaries},
+		},
+		{
+			name:    "binaries without architecture",
+			layer:   &Layer{Name: "tools", Binaries: binaries},
+			isError: true,
+		},
+		{
+			name:    "binaries with multiple architectures",
+			layer:   &Layer{Name: "tools", CompatibleArchitectures: []string{ArchitectureX86_64, ArchitectureArm64}, Binaries: binaries},
+			isError: true,
+		},
+		{
+			name:    "unsupported architecture",
+			layer:   &Layer{Name: "tools", CompatibleArchitectures: []string{"mips"}},
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.layer.Validate()
+			if tt.isError && err == nil {
+				t.Errorf("expected error, got nil")
+			} else if !tt.isError && err != nil {
+				t.Errorf("unexpected error: %s", err.Error())
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ type Resource struct {
 	Path         string                  `toml:"path"`
 	Integrations map[string]*Integration `toml:"integrations"`
 	UserDefined  bool                    `toml:"user_defined"`
+	Cors         *Cors                   `toml:"cors"`
 }
 
 func NewResource(id, path string) *Resource {
@@ -2,5 +2,37 @@ package entity
 
 type Stage struct {
 	Name      string            `toml:"name"`
+	Tracing   *bool             `toml:"tracing"`
 	Variables map[string]string `toml:"variables"`
+	Throttle  *StageThrottle    `toml:"throttle"`
+	Cache     *StageCache       `toml:"cache"`
+	AccessLog *StageAccessLog   `toml:"access_log"`
+}
+
+// Throttle is the struct which maps throttling limits of method.
+// Limits are nil if they aren't defined, and left as they are on AWS.
+type Throttle struct {
+	BurstLimit *int64   `toml:"burst_limit"`
+	RateLimit  *float64 `toml:"rate_limit"`
+}
+
+// StageThrottle is the struct which maps stage.throttle field.
+// Methods accepts "path/METHOD" key like "users/{id}/GET" to override default limits.
+type StageThrottle struct {
+	BurstLimit *int64               `toml:"burst_limit"`
+	RateLimit  *float64             `toml:"rate_limit"`
+	Methods    map[string]*Throttle `toml:"methods"`
+}
+
+// StageCache is the struct which maps stage.cache field.
+type StageCache struct {
+	Enabled     bool   `toml:"enabled"`
+	ClusterSize string `toml:"cluster_size"`
+	TTL         *int64 `toml:"ttl"`
+}
+
+// StageAccessLog is the struct which maps stage.access_log field.
+type StageAccessLog struct {
+	DestinationArn string `toml:"destination_arn"`
+	Format         string `toml:"format"`
 }
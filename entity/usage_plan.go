@@ -0,0 +1,42 @@
+package entity
+
+// Quota is the struct which maps usage_plans.quota field.
+// Period accepts on "DAY", "WEEK" or "MONTH".
+type Quota struct {
+	Limit  int64  `toml:"limit"`
+	Period string `toml:"period"`
+	Offset int64  `toml:"offset"`
+}
+
+// UsagePlan is the entity struct which maps 'usage_plans' slice in configuration.
+// Stages is the list of stage names which the plan applies to,
+// and ApiKeys is the list of api key names which are attached to the plan.
+type UsagePlan struct {
+	Id          string    `toml:"id"`
+	Name        string    `toml:"name"`
+	Description string    `toml:"description"`
+	Stages      []string  `toml:"stages"`
+	ApiKeys     []string  `toml:"api_keys"`
+	Throttle    *Throttle `toml:"throttle"`
+	Quota       *Quota    `toml:"quota"`
+}
+
+// HasApiKey() returns true if api key is attached to the plan.
+func (u *UsagePlan) HasApiKey(name string) bool {
+	for _, k := range u.ApiKeys {
+		if k == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DetachApiKey() removes api key from the plan.
+func (u *UsagePlan) DetachApiKey(name string) {
+	for i, k := range u.ApiKeys {
+		if k == name {
+			u.ApiKeys = append(u.ApiKeys[0:i], u.ApiKeys[i+1:]...)
+			return
+		}
+	}
+}
@@ -31,6 +31,28 @@ func main() {
 		Alias("delete", "", nil).
 		Alias("message", "", "").
 		Alias("update", "u", nil).
+		Alias("type", "", "").
+		Alias("function", "", "").
+		Alias("authorizer", "", "").
+		Alias("plan", "", "").
+		Alias("format", "", "").
+		Alias("file", "", "").
+		Alias("port", "", 8080).
+		Alias("invoke", "", nil).
+		Alias("watch", "", nil).
+		Alias("runtime", "", "").
+		Alias("arch", "", "").
+		Alias("module", "", "").
+		Alias("no-cache", "", nil).
+		Alias("artifact", "", "").
+		Alias("code-upload", "", "").
+		Alias("code-upload-threshold", "", 0).
+		Alias("alias", "", "").
+		Alias("version", "", "").
+		Alias("strategy", "", "").
+		Alias("to", "", "").
+		Alias("redrive", "", nil).
+		Alias("show-values", "", nil).
 		Parse(os.Args[1:])
 
 	var cmd command.Command
@@ -55,6 +77,14 @@ func main() {
 		cmd = command.NewStage()
 	case command.SCHEDULER, command.SC:
 		cmd = command.NewScheduler()
+	case command.AUTHORIZER, command.AU:
+		cmd = command.NewAuthorizer()
+	case command.APIKEY, command.AK:
+		cmd = command.NewApiKey()
+	case command.SERVE:
+		cmd = command.NewServe()
+	case command.LAYER:
+		cmd = command.NewLayer()
 	default:
 		cmd = command.NewHelp()
 	}
@@ -62,6 +92,6 @@ func main() {
 	if ctx.Has("help") || ctx.At(1) == "help" {
 		fmt.Println(cmd.Help())
 	} else if err := cmd.Run(ctx); err != nil {
-		os.Exit(1)
+		os.Exit(command.ExitStatus(err))
 	}
 }
@@ -0,0 +1,204 @@
+// Package openapi declares the subset of OpenAPI 3 document which ginger exports and imports,
+// including API Gateway extensions.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const Version = "3.0.1"
+
+// AnyMethod is the extension key for API Gateway "ANY" method.
+const AnyMethod = "x-amazon-apigateway-any-method"
+
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       *Info                 `json:"info"`
+	Servers    []*Server             `json:"servers,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components *Components           `json:"components,omitempty"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Server struct {
+	Url       string                     `json:"url"`
+	Variables map[string]*ServerVariable `json:"variables,omitempty"`
+}
+
+type ServerVariable struct {
+	Default string   `json:"default"`
+	Enum    []string `json:"enum,omitempty"`
+}
+
+// PathItem maps lower-cased HTTP method to operation.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationId string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Integration *Integration          `json:"x-amazon-apigateway-integration,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema,omitempty"`
+}
+
+type Schema struct {
+	Type string `json:"type"`
+}
+
+type Response struct {
+	Description string             `json:"description"`
+	Headers     map[string]*Header `json:"headers,omitempty"`
+}
+
+type Header struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Components struct {
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	AuthType string `json:"x-amazon-apigateway-authtype,omitempty"`
+}
+
+// Integration is the x-amazon-apigateway-integration extension.
+type Integration struct {
+	Type               string                          `json:"type"`
+	HttpMethod         string                          `json:"httpMethod,omitempty"`
+	Uri                string                          `json:"uri,omitempty"`
+	ContentHandling    string                          `json:"contentHandling,omitempty"`
+	RequestParameters  map[string]string               `json:"requestParameters,omitempty"`
+	CacheKeyParameters []string                        `json:"cacheKeyParameters,omitempty"`
+	Responses          map[string]*IntegrationResponse `json:"responses,omitempty"`
+}
+
+type IntegrationResponse struct {
+	StatusCode         string            `json:"statusCode"`
+	ResponseParameters map[string]string `json:"responseParameters,omitempty"`
+	ContentHandling    string            `json:"contentHandling,omitempty"`
+}
+
+// OperationSecurity returns security requirements of the operation.
+// Operation inherits document security unless it declares its own, and empty list means no security.
+func (d *Document) OperationSecurity(op *Operation) []map[string][]string {
+	if op.Security != nil {
+		return op.Security
+	}
+	return d.Security
+}
+
+// MethodKey converts API Gateway method to the key of path item.
+func MethodKey(method string) string {
+	if strings.ToUpper(method) == "ANY" {
+		return AnyMethod
+	}
+	return strings.ToLower(method)
+}
+
+// Method converts the key of path item to API Gateway method.
+func Method(key string) string {
+	if key == AnyMethod {
+		return "ANY"
+	}
+	return strings.ToUpper(key)
+}
+
+// PathParameters returns parameter names from "{param}" segments.
+// Greedy segment like "{proxy+}" is returned as "proxy".
+func PathParameters(path string) []string {
+	params := []string{}
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			params = append(params, strings.TrimSuffix(strings.Trim(seg, "{}"), "+"))
+		}
+	}
+	return params
+}
+
+var methodKeys = map[string]struct{}{
+	"get":     struct{}{},
+	"put":     struct{}{},
+	"post":    struct{}{},
+	"delete":  struct{}{},
+	"options": struct{}{},
+	"head":    struct{}{},
+	"patch":   struct{}{},
+	AnyMethod: struct{}{},
+}
+
+// Load decodes OpenAPI document from YAML or JSON bytes.
+// The keys of path item which aren't HTTP methods like "parameters" are ignored.
+func Load(buf []byte) (*Document, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(buf, &raw); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode document")
+	}
+	root, ok := normalize(raw).(map[string]interface{})
+	if !ok {
+		return nil, errors.New("Document must be an object")
+	}
+	if paths, ok := root["paths"].(map[string]interface{}); ok {
+		for _, item := range paths {
+			operations, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for key := range operations {
+				if _, ok := methodKeys[strings.ToLower(key)]; !ok {
+					delete(operations, key)
+				}
+			}
+		}
+	}
+	// YAML is decoded as generic value, then convert to document through JSON
+	b, err := json.Marshal(root)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to convert document")
+	}
+	doc := &Document{}
+	if err := json.Unmarshal(b, doc); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode document")
+	}
+	return doc, nil
+}
+
+// normalize converts map[interface{}]interface{} which YAML decoder makes to map[string]interface{} recursively.
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{})
+		for key, value := range t {
+			m[fmt.Sprint(key)] = normalize(value)
+		}
+		return m
+	case []interface{}:
+		for i, value := range t {
+			t[i] = normalize(value)
+		}
+		return t
+	default:
+		return v
+	}
+}
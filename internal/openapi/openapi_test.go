@@ -0,0 +1,101 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMethodKey(t *testing.T) {
+	tests := []struct {
+		method string
+		key    string
+	}{
+		{method: "GET", key: "get"},
+		{method: "post", key: "post"},
+		{method: "ANY", key: AnyMethod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			if actual := MethodKey(tt.method); actual != tt.key {
+				t.Errorf("expected %s, got %s", tt.key, actual)
+			}
+			if actual := Method(tt.key); actual != strings.ToUpper(tt.method) {
+				t.Errorf("expected %s, got %s", strings.ToUpper(tt.method), actual)
+			}
+		})
+	}
+}
+
+func TestPathParameters(t *testing.T) {
+	tests := []struct {
+		path   string
+		expect []string
+	}{
+		{path: "/users", expect: []string{}},
+		{path: "/users/{id}", expect: []string{"id"}},
+		{path: "/users/{id}/posts/{postId}", expect: []string{"id", "postId"}},
+		{path: "/static/{proxy+}", expect: []string{"proxy"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if actual := PathParameters(tt.path); !reflect.DeepEqual(actual, tt.expect) {
+				t.Errorf("expected %v, got %v", tt.expect, actual)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	yaml := `
+openapi: 3.0.1
+info:
+  title: example
+  version: "1.0.0"
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+    get:
+      operationId: getUser
+      security:
+        - api_key: []
+    x-amazon-apigateway-any-method:
+      operationId: anyUser
+`
+	json := `{"openapi":"3.0.1","info":{"title":"example","version":"1.0.0"},"paths":{"/users":{"post":{"operationId":"createUser"}}}}`
+
+	for name, src := range map[string]string{"yaml": yaml, "json": json} {
+		t.Run(name, func(t *testing.T) {
+			doc, err := Load([]byte(src))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if doc.Info.Title != "example" {
+				t.Errorf("expected title example, got %s", doc.Info.Title)
+			}
+			for _, item := range doc.Paths {
+				for key, op := range item {
+					if op == nil || op.OperationId == "" {
+						t.Errorf("operation %s isn't decoded", key)
+					}
+				}
+			}
+		})
+	}
+
+	doc, _ := Load([]byte(yaml))
+	item := doc.Paths["/users/{id}"]
+	if len(item) != 2 || item["get"] == nil || item[AnyMethod] == nil {
+		t.Errorf("expected get and any method operations, got %v", item)
+	} else if len(item["get"].Security) != 1 {
+		t.Errorf("expected security of get operation is decoded")
+	}
+
+	if _, err := Load([]byte("- list")); err == nil {
+		t.Errorf("expected error for non-object document")
+	}
+}
@@ -11,6 +11,12 @@ import (
 
 var stdout = colorable.NewColorableStdout()
 
+// UseStderr() switches output of all loggers to stderr.
+// It's used when command writes its result to stdout, e.g. exporting document.
+func UseStderr() {
+	stdout = colorable.NewColorableStderr()
+}
+
 // Logger is the struct that outputs colored log with namespace.
 type Logger struct {
 	ns string
@@ -2,6 +2,7 @@ package request
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -148,6 +149,27 @@ func (a *APIGatewayRequest) GetResourceIdByPath(restId, path string) (string, er
 	return "", fmt.Errorf("%s path not found in resources", path)
 }
 
+// GetResources returns all resources which belong to the REST API, including embedded methods.
+func (a *APIGatewayRequest) GetResources(restId string) ([]*apigateway.Resource, error) {
+	input := &apigateway.GetResourcesInput{
+		RestApiId: aws.String(restId),
+		Embed:     []*string{aws.String("methods")},
+		Limit:     aws.Int64(500),
+	}
+	debugRequest(input)
+	resources := []*apigateway.Resource{}
+	err := a.svc.GetResourcesPages(input, func(page *apigateway.GetResourcesOutput, lastPage bool) bool {
+		debugRequest(page)
+		resources = append(resources, page.Items...)
+		return !lastPage
+	})
+	if err != nil {
+		a.errorLog(err, apigateway.ErrCodeNotFoundException)
+		return nil, err
+	}
+	return resources, nil
+}
+
 func (a *APIGatewayRequest) CreateResource(restId, parentId, pathPart string) (string, error) {
 	a.log.Printf("Creating resource for path part \"%s\"...\n", pathPart)
 	input := &apigateway.CreateResourceInput{
@@ -190,51 +212,86 @@ func (a *APIGatewayRequest) CreateResourceRecursive(restId, path string) (err er
 	return nil
 }
 
-func (a *APIGatewayRequest) PutIntegration(restId, resourceId, method string, i *entity.Integration) (err error) {
+// PutIntegration puts method, integration and responses for the integration.
+// If cors is supplied, Access-Control-* headers are added to method response.
+func (a *APIGatewayRequest) PutIntegration(restId, resourceId, method string, i *entity.Integration, cors *entity.Cors) (err error) {
+	corsHeaders := map[string]string{}
+	if cors != nil {
+		corsHeaders["Access-Control-Allow-Origin"] = cors.Origin()
+		if cors.AllowCredentials {
+			corsHeaders["Access-Control-Allow-Credentials"] = "true"
+		}
+	}
+	corsMethodParams, corsIntegrationParams := corsResponseParameters(corsHeaders)
+
 	switch i.IntegrationType {
 	case "lambda":
-		fn, err := a.config.LoadFunction(*i.LambdaFunction)
-		if err != nil {
-			err = fmt.Errorf("Function %s couldn't find in your project.\n", *i.LambdaFunction)
+		name, _ := entity.SplitQualifier(*i.LambdaFunction)
+		if _, err := a.config.LoadFunction(name); err != nil {
+			err = fmt.Errorf("Function %s couldn't find in your project.\n", name)
 			a.errorLog(err)
 			return err
 		}
-		a.PutMethod(restId, resourceId, method, nil)
-		if err = a.putLambdaIntegration(restId, resourceId, method, i.Path, fn); err != nil {
+		if err = a.PutMethod(restId, resourceId, method, i, nil); err != nil {
+			return err
+		}
+		if err = a.putLambdaIntegration(restId, resourceId, method, i.Path, *i.LambdaFunction); err != nil {
 			a.errorLog(err)
 			return err
 		}
-		return a.PutMethodResponse(restId, resourceId, method, 200, nil, nil)
+		// Lambda proxy integration cannot map response headers, so function should respond them
+		return a.PutMethodResponse(restId, resourceId, method, 200, corsMethodParams, nil)
 	case "s3":
-		a.PutMethod(restId, resourceId, method, map[string]*bool{
+		if err = a.PutMethod(restId, resourceId, method, i, map[string]*bool{
 			"method.request.path.proxy": aws.Bool(true),
-		})
+		}); err != nil {
+			return err
+		}
 		if err = a.putS3Integration(restId, resourceId, method, *i.BucketPath); err != nil {
 			a.errorLog(err)
 			return err
 		}
-		err = a.PutMethodResponse(restId, resourceId, method, 200, map[string]*bool{
-			"method.response.header.Content-Type": aws.Bool(false),
-		}, map[string]*string{
+		corsMethodParams["method.response.header.Content-Type"] = aws.Bool(false)
+		err = a.PutMethodResponse(restId, resourceId, method, 200, corsMethodParams, map[string]*string{
 			"application/json": aws.String("Empty"),
 		})
 		if err != nil {
 			a.errorLog(err)
 			return err
 		}
-		return a.PutS3IntegrationResponse(restId, resourceId, method)
+		return a.PutS3IntegrationResponse(restId, resourceId, method, corsIntegrationParams)
 	default:
 		a.log.Errorf("Unexpected integration type %s\n", i.IntegrationType)
-		return nil
+		return fmt.Errorf("Unexpected integration type %s", i.IntegrationType)
+	}
+}
+
+// methodAuthorization resolves authorization type and authorizer id from integration.
+func (a *APIGatewayRequest) methodAuthorization(i *entity.Integration) (string, *string, error) {
+	if i == nil || i.Authorizer == nil || *i.Authorizer == "" {
+		return "NONE", nil, nil
 	}
+	au, err := a.config.LoadAuthorizer(*i.Authorizer)
+	if err != nil {
+		return "", nil, fmt.Errorf("Authorizer %s couldn't find in your project", *i.Authorizer)
+	} else if au.Id == "" {
+		return "", nil, fmt.Errorf("Authorizer %s hasn't been deployed yet", au.Name)
+	}
+	return au.AuthorizationType(), aws.String(au.Id), nil
 }
 
-func (a *APIGatewayRequest) PutMethod(restId, resourceId, httpMethod string, requestParameters map[string]*bool) error {
+func (a *APIGatewayRequest) PutMethod(restId, resourceId, httpMethod string, i *entity.Integration, requestParameters map[string]*bool) error {
 	a.log.Printf("Putting \"%s\" method for resource \"%s\"...\n", httpMethod, resourceId)
+	authorizationType, authorizerId, err := a.methodAuthorization(i)
+	if err != nil {
+		a.errorLog(err)
+		return err
+	}
+	apiKeyRequired := i != nil && i.ApiKeyRequired
 	input := &apigateway.PutMethodInput{
-		// TODO: avoid hard code
-		ApiKeyRequired:    aws.Bool(false),
-		AuthorizationType: aws.String("NONE"),
+		ApiKeyRequired:    aws.Bool(apiKeyRequired),
+		AuthorizationType: aws.String(authorizationType),
+		AuthorizerId:      authorizerId,
 		HttpMethod:        aws.String(httpMethod),
 		ResourceId:        aws.String(resourceId),
 		RestApiId:         aws.String(restId),
@@ -245,7 +302,12 @@ func (a *APIGatewayRequest) PutMethod(restId, resourceId, httpMethod string, req
 	debugRequest(input)
 	result, err := a.svc.PutMethod(input)
 	if err != nil {
-		a.errorLog(err, apigateway.ErrCodeConflictException)
+		// ConflictException means method has already been put, so update authorization settings
+		if a.ignoreErrors(err, apigateway.ErrCodeConflictException) {
+			a.log.Info("Method has already been put.")
+			return a.updateMethodAuthorization(restId, resourceId, httpMethod, authorizationType, authorizerId, apiKeyRequired)
+		}
+		a.errorLog(err)
 		return err
 	}
 	debugRequest(result)
@@ -253,6 +315,58 @@ func (a *APIGatewayRequest) PutMethod(restId, resourceId, httpMethod string, req
 	return nil
 }
 
+// updateMethodAuthorization updates authorization settings of existing method.
+// If the method doesn't use authorizer anymore, attached authorizer id is removed as well.
+func (a *APIGatewayRequest) updateMethodAuthorization(restId, resourceId, httpMethod, authorizationType string, authorizerId *string, apiKeyRequired bool) error {
+	operations := []*apigateway.PatchOperation{
+		&apigateway.PatchOperation{
+			Op:    aws.String("replace"),
+			Path:  aws.String("/authorizationType"),
+			Value: aws.String(authorizationType),
+		},
+		&apigateway.PatchOperation{
+			Op:    aws.String("replace"),
+			Path:  aws.String("/apiKeyRequired"),
+			Value: aws.String(fmt.Sprint(apiKeyRequired)),
+		},
+	}
+	if authorizerId != nil {
+		operations = append(operations, &apigateway.PatchOperation{
+			Op:    aws.String("replace"),
+			Path:  aws.String("/authorizerId"),
+			Value: authorizerId,
+		})
+	} else if current, err := a.svc.GetMethod(&apigateway.GetMethodInput{
+		HttpMethod: aws.String(httpMethod),
+		ResourceId: aws.String(resourceId),
+		RestApiId:  aws.String(restId),
+	}); err != nil {
+		a.errorLog(err)
+		return err
+	} else if current.AuthorizerId != nil && *current.AuthorizerId != "" {
+		// Authorizer stays attached to the method unless it's removed explicitly
+		operations = append(operations, &apigateway.PatchOperation{
+			Op:   aws.String("remove"),
+			Path: aws.String("/authorizerId"),
+		})
+	}
+	input := &apigateway.UpdateMethodInput{
+		HttpMethod:      aws.String(httpMethod),
+		ResourceId:      aws.String(resourceId),
+		RestApiId:       aws.String(restId),
+		PatchOperations: operations,
+	}
+	debugRequest(input)
+	result, err := a.svc.UpdateMethod(input)
+	if err != nil {
+		a.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	a.log.Info("Update method authorization successfully.")
+	return nil
+}
+
 func (a *APIGatewayRequest) PutMethodResponse(restId, resourceId, httpMethod string, statusCode int, responseParameters map[string]*bool, responseModels map[string]*string) error {
 	a.log.Printf("Putting \"%s\" method response for resource \"%s\"...\n", httpMethod, resourceId)
 	input := &apigateway.PutMethodResponseInput{
@@ -274,12 +388,64 @@ func (a *APIGatewayRequest) PutMethodResponse(restId, resourceId, httpMethod str
 			a.errorLog(err, apigateway.ErrCodeConflictException)
 			return err
 		}
+		// Method response has already been put, but integration response may refer new parameters
+		return a.addMethodResponseParameters(restId, resourceId, httpMethod, statusCode, responseParameters)
 	}
 	debugRequest(result)
 	a.log.Info("Put method response successfully.")
 	return nil
 }
 
+// addMethodResponseParameters adds response parameters which don't exist in method response.
+func (a *APIGatewayRequest) addMethodResponseParameters(restId, resourceId, httpMethod string, statusCode int, responseParameters map[string]*bool) error {
+	if len(responseParameters) == 0 {
+		return nil
+	}
+	getInput := &apigateway.GetMethodResponseInput{
+		HttpMethod: aws.String(httpMethod),
+		ResourceId: aws.String(resourceId),
+		RestApiId:  aws.String(restId),
+		StatusCode: aws.String(fmt.Sprint(statusCode)),
+	}
+	debugRequest(getInput)
+	response, err := a.svc.GetMethodResponse(getInput)
+	if err != nil {
+		a.errorLog(err)
+		return err
+	}
+	debugRequest(response)
+	operations := []*apigateway.PatchOperation{}
+	for key, required := range responseParameters {
+		if _, ok := response.ResponseParameters[key]; ok {
+			continue
+		}
+		operations = append(operations, &apigateway.PatchOperation{
+			Op:    aws.String("add"),
+			Path:  aws.String("/responseParameters/" + key),
+			Value: aws.String(fmt.Sprint(aws.BoolValue(required))),
+		})
+	}
+	if len(operations) == 0 {
+		return nil
+	}
+	input := &apigateway.UpdateMethodResponseInput{
+		HttpMethod:      aws.String(httpMethod),
+		ResourceId:      aws.String(resourceId),
+		RestApiId:       aws.String(restId),
+		StatusCode:      aws.String(fmt.Sprint(statusCode)),
+		PatchOperations: operations,
+	}
+	debugRequest(input)
+	result, err := a.svc.UpdateMethodResponse(input)
+	if err != nil {
+		a.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	a.log.Info("Update method response successfully.")
+	return nil
+}
+
 func (a *APIGatewayRequest) generateIntegrationUri(lambdaArn *string) string {
 	return fmt.Sprintf(
 		"arn:aws:apigateway:%s:lambda:path/2015-03-31/functions/%s/invocations",
@@ -328,17 +494,21 @@ func (a *APIGatewayRequest) putS3Integration(restId, resourceId, httpMethod, buc
 	return nil
 }
 
-func (a *APIGatewayRequest) PutS3IntegrationResponse(restId, resourceId, httpMethod string) error {
+func (a *APIGatewayRequest) PutS3IntegrationResponse(restId, resourceId, httpMethod string, responseParameters map[string]*string) error {
 	a.log.Printf("Putting S3 integration response\n")
 
+	parameters := map[string]*string{
+		"method.response.header.Content-Type": aws.String("integration.response.header.Content-Type"),
+	}
+	for key, value := range responseParameters {
+		parameters[key] = value
+	}
 	input := &apigateway.PutIntegrationResponseInput{
-		HttpMethod: aws.String(httpMethod),
-		RestApiId:  aws.String(restId),
-		ResourceId: aws.String(resourceId),
-		StatusCode: aws.String("200"),
-		ResponseParameters: map[string]*string{
-			"method.response.header.Content-Type": aws.String("integration.response.header.Content-Type"),
-		},
+		HttpMethod:         aws.String(httpMethod),
+		RestApiId:          aws.String(restId),
+		ResourceId:         aws.String(resourceId),
+		StatusCode:         aws.String("200"),
+		ResponseParameters: parameters,
 		ResponseTemplates: map[string]*string{
 			"application/json": aws.String(""),
 		},
@@ -356,18 +526,109 @@ func (a *APIGatewayRequest) PutS3IntegrationResponse(restId, resourceId, httpMet
 	return nil
 }
 
-func (a *APIGatewayRequest) putLambdaIntegration(restId, resourceId, httpMethod, path string, fn *entity.Function) error {
+// corsResponseParameters makes method response parameters and integration response mappings from header values.
+func corsResponseParameters(headers map[string]string) (map[string]*bool, map[string]*string) {
+	methodParams := map[string]*bool{}
+	integrationParams := map[string]*string{}
+	for name, value := range headers {
+		key := "method.response.header." + name
+		methodParams[key] = aws.Bool(false)
+		integrationParams[key] = aws.String("'" + value + "'")
+	}
+	return methodParams, integrationParams
+}
+
+// corsOriginTemplate makes response template which responds requested origin if it is allowed.
+// Access-Control-Allow-Origin header accepts only one origin, so static mapping is overridden on request.
+func corsOriginTemplate(cors *entity.Cors) string {
+	if len(cors.AllowOrigins) < 2 {
+		return ""
+	}
+	conditions := []string{}
+	for _, origin := range cors.AllowOrigins {
+		conditions = append(conditions, fmt.Sprintf("$origin == \"%s\"", origin))
+	}
+	return strings.Join([]string{
+		"#set($origin = $input.params().header.get(\"Origin\"))",
+		"#if(" + strings.Join(conditions, " || ") + ")",
+		"#set($context.responseOverride.header.Access-Control-Allow-Origin = $origin)",
+		"#end",
+	}, "\n")
+}
+
+// PutCors puts OPTIONS method with mock integration which responds CORS preflight.
+func (a *APIGatewayRequest) PutCors(restId, resourceId string, cors *entity.Cors, integrations map[string]*entity.Integration) error {
+	a.log.Printf("Putting CORS preflight for resource \"%s\"...\n", resourceId)
+	methodParams, integrationParams := corsResponseParameters(cors.ResponseHeaders(integrations))
+	if err := a.PutMethod(restId, resourceId, "OPTIONS", nil, nil); err != nil {
+		return err
+	}
+	if err := a.putMockIntegration(restId, resourceId, "OPTIONS"); err != nil {
+		return err
+	}
+	err := a.PutMethodResponse(restId, resourceId, "OPTIONS", 200, methodParams, map[string]*string{
+		"application/json": aws.String("Empty"),
+	})
+	if err != nil {
+		return err
+	}
+	input := &apigateway.PutIntegrationResponseInput{
+		HttpMethod:         aws.String("OPTIONS"),
+		RestApiId:          aws.String(restId),
+		ResourceId:         aws.String(resourceId),
+		StatusCode:         aws.String("200"),
+		ResponseParameters: integrationParams,
+		ResponseTemplates: map[string]*string{
+			"application/json": aws.String(corsOriginTemplate(cors)),
+		},
+	}
+	debugRequest(input)
+	result, err := a.svc.PutIntegrationResponse(input)
+	if err != nil {
+		a.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	a.log.Info("Put CORS preflight successfully.")
+	return nil
+}
+
+func (a *APIGatewayRequest) putMockIntegration(restId, resourceId, httpMethod string) error {
+	a.log.Print("Putting mock integration...")
+	input := &apigateway.PutIntegrationInput{
+		HttpMethod: aws.String(httpMethod),
+		Type:       aws.String("MOCK"),
+		ResourceId: aws.String(resourceId),
+		RestApiId:  aws.String(restId),
+		RequestTemplates: map[string]*string{
+			"application/json": aws.String(`{"statusCode": 200}`),
+		},
+	}
+	debugRequest(input)
+	result, err := a.svc.PutIntegration(input)
+	if err != nil {
+		a.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	a.log.Print("Put integration successfully.")
+	return nil
+}
+
+// putLambdaIntegration puts lambda proxy integration.
+// Function reference can be qualified by alias like "name:alias", then integration invokes alias.
+func (a *APIGatewayRequest) putLambdaIntegration(restId, resourceId, httpMethod, path, ref string) error {
 	a.log.Printf("Putting Lambda integration for %s...\n", path)
 
 	l := NewLambda(a.config)
-	fnConfig, err := l.GetFunction(fn.Name)
+	arn, err := l.ResolveFunctionArn(ref)
 	if err != nil {
 		return err
 	}
 	input := &apigateway.PutIntegrationInput{
 		HttpMethod:            aws.String(httpMethod),
 		Type:                  aws.String("AWS_PROXY"),
-		Uri:                   aws.String(a.generateIntegrationUri(fnConfig.FunctionArn)),
+		Uri:                   aws.String(a.generateIntegrationUri(arn)),
 		ResourceId:            aws.String(resourceId),
 		RestApiId:             aws.String(restId),
 		IntegrationHttpMethod: aws.String("POST"),
@@ -386,9 +647,10 @@ func (a *APIGatewayRequest) putLambdaIntegration(restId, resourceId, httpMethod,
 		return err
 	}
 	sourceArn := a.generateSourceArn(account, restId, httpMethod, path)
-	if err := l.AddAPIGatewayPermission(fn.Name, sourceArn); err == nil {
-		a.log.Info("Put integration successfully.")
+	if err := l.AddAPIGatewayPermission(ref, sourceArn); err != nil {
+		return err
 	}
+	a.log.Info("Put integration successfully.")
 	return nil
 }
 
@@ -413,6 +675,455 @@ func (a *APIGatewayRequest) Deploy(restId, stage, description string) error {
 	return nil
 }
 
+// GetStage gets stage detail.
+func (a *APIGatewayRequest) GetStage(restId, stageName string) (*apigateway.Stage, error) {
+	input := &apigateway.GetStageInput{
+		StageName: aws.String(stageName),
+		RestApiId: aws.String(restId),
+	}
+	debugRequest(input)
+	result, err := a.svc.GetStage(input)
+	if err != nil {
+		a.errorLog(err, apigateway.ErrCodeNotFoundException)
+		return nil, err
+	}
+	debugRequest(result)
+	return result, nil
+}
+
+// UpdateStageVariables syncs stage variables to stage via patch operations.
+// The variables which don't exist in local are removed from stage.
+func (a *APIGatewayRequest) UpdateStageVariables(restId, stageName string, variables map[string]string) error {
+	a.log.Printf("Updating stage variables for stage: %s...\n", stageName)
+	stage, err := a.GetStage(restId, stageName)
+	if err != nil {
+		return err
+	}
+	operations := []*apigateway.PatchOperation{}
+	for key, value := range variables {
+		if v, ok := stage.Variables[key]; ok && aws.StringValue(v) == value {
+			continue
+		}
+		operations = append(operations, &apigateway.PatchOperation{
+			Op:    aws.String("replace"),
+			Path:  aws.String("/variables/" + key),
+			Value: aws.String(value),
+		})
+	}
+	for key := range stage.Variables {
+		if _, ok := variables[key]; ok {
+			continue
+		}
+		operations = append(operations, &apigateway.PatchOperation{
+			Op:   aws.String("remove"),
+			Path: aws.String("/variables/" + key),
+		})
+	}
+	if len(operations) == 0 {
+		a.log.Info("Stage variables are up-to-date.")
+		return nil
+	}
+	input := &apigateway.UpdateStageInput{
+		RestApiId:       aws.String(restId),
+		StageName:       aws.String(stageName),
+		PatchOperations: operations,
+	}
+	debugRequest(input)
+	result, err := a.svc.UpdateStage(input)
+	if err != nil {
+		a.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	a.log.Infof("Stage variables updated successfully: %d operation(s).\n", len(operations))
+	return nil
+}
+
+// formatMethodSettingPath makes method setting path for patch operation from "path/METHOD" string.
+// Slashes in resource path must be escaped as "~1", e.g. "users/GET" becomes "/~1users/GET".
+func formatMethodSettingPath(key string) string {
+	index := strings.LastIndex(key, "/")
+	if index == -1 {
+		return "/~1/" + strings.ToUpper(key)
+	}
+	path := "/" + strings.Trim(key[0:index], "/")
+	return "/" + strings.Replace(path, "/", "~1", -1) + "/" + strings.ToUpper(key[index+1:])
+}
+
+// methodSettingKeyPath makes method setting path for patch operation from key of stage method settings.
+// AWS returns key as "path/METHOD" and path may be already escaped like "~1users/GET".
+func methodSettingKeyPath(key string) string {
+	if key == "*/*" {
+		return "/*/*"
+	}
+	return formatMethodSettingPath(strings.Replace(key, "~1", "/", -1))
+}
+
+// isThrottlingOverride returns true if method setting differs from stage's default setting only in throttling.
+func isThrottlingOverride(setting, defaults *apigateway.MethodSetting) bool {
+	if setting == nil || defaults == nil {
+		return false
+	}
+	s, d := *setting, *defaults
+	s.ThrottlingBurstLimit, s.ThrottlingRateLimit = nil, nil
+	d.ThrottlingBurstLimit, d.ThrottlingRateLimit = nil, nil
+	return reflect.DeepEqual(s, d)
+}
+
+// UpdateStageSettings applies throttling, caching, access logging and tracing settings to stage.
+// The settings which aren't defined in local are left as they are,
+// but throttling of method overrides which don't exist in local is reset to stage's one if [throttle] is defined.
+func (a *APIGatewayRequest) UpdateStageSettings(restId string, stage *entity.Stage) error {
+	a.log.Printf("Updating stage settings for stage: %s...\n", stage.Name)
+	operations := []*apigateway.PatchOperation{}
+	replace := func(path, value string) {
+		operations = append(operations, &apigateway.PatchOperation{
+			Op:    aws.String("replace"),
+			Path:  aws.String(path),
+			Value: aws.String(value),
+		})
+	}
+	throttle := func(path string, burstLimit *int64, rateLimit *float64) {
+		if burstLimit != nil {
+			replace(path+"/throttling/burstLimit", fmt.Sprint(*burstLimit))
+		}
+		if rateLimit != nil {
+			replace(path+"/throttling/rateLimit", fmt.Sprint(*rateLimit))
+		}
+	}
+
+	if t := stage.Throttle; t != nil {
+		throttle("/*/*", t.BurstLimit, t.RateLimit)
+		declared := map[string]struct{}{}
+		for key, mt := range t.Methods {
+			path := formatMethodSettingPath(key)
+			declared[path] = struct{}{}
+			throttle(path, mt.BurstLimit, mt.RateLimit)
+		}
+		current, err := a.GetStage(restId, stage.Name)
+		if err != nil {
+			return err
+		}
+		defaults := current.MethodSettings["*/*"]
+		burstLimit, rateLimit := t.BurstLimit, t.RateLimit
+		if defaults != nil {
+			if burstLimit == nil {
+				burstLimit = defaults.ThrottlingBurstLimit
+			}
+			if rateLimit == nil {
+				rateLimit = defaults.ThrottlingRateLimit
+			}
+		}
+		for key, setting := range current.MethodSettings {
+			path := methodSettingKeyPath(key)
+			if path == "/*/*" {
+				continue
+			} else if _, ok := declared[path]; ok {
+				continue
+			}
+			// Override may have other settings like logging and caching, so remove it only if it overrides throttling only,
+			// otherwise reset throttling to the stage's one
+			if isThrottlingOverride(setting, defaults) {
+				operations = append(operations, &apigateway.PatchOperation{
+					Op:   aws.String("remove"),
+					Path: aws.String(path),
+				})
+			} else {
+				throttle(path, burstLimit, rateLimit)
+			}
+		}
+	}
+	if cc := stage.Cache; cc != nil {
+		replace("/cacheClusterEnabled", fmt.Sprint(cc.Enabled))
+		if cc.Enabled {
+			if cc.ClusterSize != "" {
+				replace("/cacheClusterSize", cc.ClusterSize)
+			}
+			replace("/*/*/caching/enabled", "true")
+			if cc.TTL != nil {
+				replace("/*/*/caching/ttlInSeconds", fmt.Sprint(*cc.TTL))
+			}
+		} else {
+			replace("/*/*/caching/enabled", "false")
+		}
+	}
+	if al := stage.AccessLog; al != nil {
+		if al.DestinationArn == "" {
+			operations = append(operations, &apigateway.PatchOperation{
+				Op:   aws.String("remove"),
+				Path: aws.String("/accessLogSettings"),
+			})
+		} else {
+			replace("/accessLogSettings/destinationArn", al.DestinationArn)
+			if al.Format != "" {
+				replace("/accessLogSettings/format", al.Format)
+			}
+		}
+	}
+	if stage.Tracing != nil {
+		replace("/tracingEnabled", fmt.Sprint(*stage.Tracing))
+	}
+
+	if len(operations) == 0 {
+		a.log.Info("No stage settings defined. Skip it.")
+		return nil
+	}
+	input := &apigateway.UpdateStageInput{
+		RestApiId:       aws.String(restId),
+		StageName:       aws.String(stage.Name),
+		PatchOperations: operations,
+	}
+	debugRequest(input)
+	result, err := a.svc.UpdateStage(input)
+	if err != nil {
+		a.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	a.log.Info("Stage settings updated successfully.")
+	return nil
+}
+
+// GetAuthorizers lists authorizers which belong to the REST API.
+func (a *APIGatewayRequest) GetAuthorizers(restId string) []*apigateway.Authorizer {
+	input := &apigateway.GetAuthorizersInput{
+		RestApiId: aws.String(restId),
+		Limit:     aws.Int64(500),
+	}
+	debugRequest(input)
+	result, err := a.svc.GetAuthorizers(input)
+	if err != nil {
+		a.errorLog(err, apigateway.ErrCodeNotFoundException)
+		return make([]*apigateway.Authorizer, 0)
+	}
+	debugRequest(result)
+	return result.Items
+}
+
+func (a *APIGatewayRequest) AuthorizerExists(restId, authorizerId string) bool {
+	return a.getAuthorizer(restId, authorizerId) != nil
+}
+
+// getAuthorizer gets authorizer, or returns nil if not found.
+func (a *APIGatewayRequest) getAuthorizer(restId, authorizerId string) *apigateway.Authorizer {
+	input := &apigateway.GetAuthorizerInput{
+		RestApiId:    aws.String(restId),
+		AuthorizerId: aws.String(authorizerId),
+	}
+	debugRequest(input)
+	result, err := a.svc.GetAuthorizer(input)
+	if err != nil {
+		a.errorLog(err, apigateway.ErrCodeNotFoundException)
+		return nil
+	}
+	debugRequest(result)
+	return result
+}
+
+// PutAuthorizer creates or updates authorizer, and set created id to the entity.
+// If authorizer type is changed, authorizer is recreated because type cannot be updated.
+func (a *APIGatewayRequest) PutAuthorizer(restId string, au *entity.Authorizer) (err error) {
+	var uri *string
+	if au.IsLambda() {
+		fn, err := NewLambda(a.config).GetFunction(au.Function)
+		if err != nil {
+			return fmt.Errorf("Authorizer function %s couldn't find in AWS: %s", au.Function, err.Error())
+		}
+		uri = aws.String(a.generateIntegrationUri(fn.FunctionArn))
+	}
+	var current *apigateway.Authorizer
+	if au.Id != "" {
+		current = a.getAuthorizer(restId, au.Id)
+	}
+	if current != nil && aws.StringValue(current.Type) != au.Type {
+		// Authorizer type cannot be changed, so recreate it.
+		// Methods are attached to new authorizer again on resource deployment
+		a.log.Printf("Authorizer type is changed from %s to %s, recreate it\n", aws.StringValue(current.Type), au.Type)
+		if err := a.detachAuthorizer(restId, au); err != nil {
+			return err
+		}
+		if err := a.DeleteAuthorizer(restId, au.Id); err != nil {
+			return err
+		}
+		current = nil
+	}
+	if current != nil {
+		if err := a.updateAuthorizer(restId, au, current, uri); err != nil {
+			return err
+		}
+	} else if au.Id, err = a.createAuthorizer(restId, au, uri); err != nil {
+		return err
+	}
+	if !au.IsLambda() {
+		return nil
+	}
+	// Add permission to lambda in order to invoke from authorizer.
+	// Function may be changed on update, so permission is put on every time with fixed statement id.
+	account, err := NewSts(a.config).GetAccount()
+	if err != nil {
+		return err
+	}
+	sourceArn := fmt.Sprintf(
+		"arn:aws:execute-api:%s:%s:%s/authorizers/%s",
+		a.config.Region,
+		account,
+		restId,
+		au.Id,
+	)
+	return NewLambda(a.config).PutAPIGatewayPermission(au.Function, sourceArn, fmt.Sprintf("ginger-authorizer-%s-%s", restId, au.Id))
+}
+
+func (a *APIGatewayRequest) createAuthorizer(restId string, au *entity.Authorizer, uri *string) (string, error) {
+	a.log.Printf("Creating authorizer %s...\n", au.Name)
+	input := &apigateway.CreateAuthorizerInput{
+		RestApiId:                    aws.String(restId),
+		Name:                         aws.String(au.Name),
+		Type:                         aws.String(au.Type),
+		AuthorizerUri:                uri,
+		AuthorizerResultTtlInSeconds: aws.Int64(au.TTL),
+	}
+	if au.IdentitySource != "" {
+		input = input.SetIdentitySource(au.IdentitySource)
+	}
+	if len(au.ProviderArns) > 0 {
+		input = input.SetProviderARNs(aws.StringSlice(au.ProviderArns))
+	}
+	debugRequest(input)
+	result, err := a.svc.CreateAuthorizer(input)
+	if err != nil {
+		a.errorLog(err)
+		return "", err
+	}
+	debugRequest(result)
+	a.log.Infof("Authorizer created successfully. Id is %s\n", *result.Id)
+	return *result.Id, nil
+}
+
+func (a *APIGatewayRequest) updateAuthorizer(restId string, au *entity.Authorizer, current *apigateway.Authorizer, uri *string) error {
+	a.log.Printf("Updating authorizer %s...\n", au.Name)
+	input := &apigateway.UpdateAuthorizerInput{
+		RestApiId:       aws.String(restId),
+		AuthorizerId:    aws.String(au.Id),
+		PatchOperations: authorizerPatchOperations(au, current, uri),
+	}
+	debugRequest(input)
+	result, err := a.svc.UpdateAuthorizer(input)
+	if err != nil {
+		a.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	a.log.Info("Authorizer updated successfully.")
+	return nil
+}
+
+// authorizerPatchOperations makes patch operations to update current authorizer to local one.
+// Identity source is left as it is if empty, and provider ARNs are synced to local.
+func authorizerPatchOperations(au *entity.Authorizer, current *apigateway.Authorizer, uri *string) []*apigateway.PatchOperation {
+	operations := []*apigateway.PatchOperation{
+		&apigateway.PatchOperation{
+			Op:    aws.String("replace"),
+			Path:  aws.String("/name"),
+			Value: aws.String(au.Name),
+		},
+		&apigateway.PatchOperation{
+			Op:    aws.String("replace"),
+			Path:  aws.String("/authorizerResultTtlInSeconds"),
+			Value: aws.String(fmt.Sprint(au.TTL)),
+		},
+	}
+	if au.IdentitySource != "" {
+		operations = append(operations, &apigateway.PatchOperation{
+			Op:    aws.String("replace"),
+			Path:  aws.String("/identitySource"),
+			Value: aws.String(au.IdentitySource),
+		})
+	}
+	if uri != nil {
+		operations = append(operations, &apigateway.PatchOperation{
+			Op:    aws.String("replace"),
+			Path:  aws.String("/authorizerUri"),
+			Value: uri,
+		})
+	}
+
+	attached := map[string]bool{}
+	for _, arn := range current.ProviderARNs {
+		attached[aws.StringValue(arn)] = true
+	}
+	for _, arn := range au.ProviderArns {
+		if _, ok := attached[arn]; ok {
+			delete(attached, arn)
+			continue
+		}
+		operations = append(operations, &apigateway.PatchOperation{
+			Op:    aws.String("add"),
+			Path:  aws.String("/providerARNs"),
+			Value: aws.String(arn),
+		})
+	}
+	for arn := range attached {
+		operations = append(operations, &apigateway.PatchOperation{
+			Op:    aws.String("remove"),
+			Path:  aws.String("/providerARNs"),
+			Value: aws.String(arn),
+		})
+	}
+	return operations
+}
+
+// detachAuthorizer detaches authorizer from the methods which refer it in local resources,
+// because authorizer cannot be deleted while it's used.
+func (a *APIGatewayRequest) detachAuthorizer(restId string, au *entity.Authorizer) error {
+	for _, r := range a.config.Resources {
+		if r.Id == "" {
+			continue
+		}
+		for method, ig := range r.GetIntegrations() {
+			if ig.Authorizer == nil || *ig.Authorizer != au.Name {
+				continue
+			}
+			current, err := a.svc.GetMethod(&apigateway.GetMethodInput{
+				HttpMethod: aws.String(method),
+				ResourceId: aws.String(r.Id),
+				RestApiId:  aws.String(restId),
+			})
+			if err != nil {
+				// Method which hasn't been deployed yet doesn't refer the authorizer
+				if a.ignoreErrors(err, apigateway.ErrCodeNotFoundException) {
+					continue
+				}
+				a.errorLog(err)
+				return err
+			} else if aws.StringValue(current.AuthorizerId) != au.Id {
+				continue
+			}
+			a.log.Printf("Detaching authorizer %s from %s %s...\n", au.Name, method, r.Path)
+			if err := a.updateMethodAuthorization(restId, r.Id, method, "NONE", nil, aws.BoolValue(current.ApiKeyRequired)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (a *APIGatewayRequest) DeleteAuthorizer(restId, authorizerId string) error {
+	a.log.Print("Deleting authorizer...")
+	input := &apigateway.DeleteAuthorizerInput{
+		RestApiId:    aws.String(restId),
+		AuthorizerId: aws.String(authorizerId),
+	}
+	debugRequest(input)
+	result, err := a.svc.DeleteAuthorizer(input)
+	if err != nil {
+		a.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	a.log.Info("Authorizer deleted successfully.")
+	return nil
+}
+
 func (a *APIGatewayRequest) DeleteRestApi(restId string) error {
 	a.log.Print("Deleting REST API...")
 	input := &apigateway.DeleteRestApiInput{
@@ -533,6 +1244,15 @@ func (a *APIGatewayRequest) StageExists(restId, stageName string) bool {
 }
 
 func (a *APIGatewayRequest) GetStages(restId string) []*apigateway.Stage {
+	stages, err := a.ListStages(restId)
+	if err != nil {
+		return make([]*apigateway.Stage, 0)
+	}
+	return stages
+}
+
+// ListStages lists stages of the REST API, and returns error if failed.
+func (a *APIGatewayRequest) ListStages(restId string) ([]*apigateway.Stage, error) {
 	input := &apigateway.GetStagesInput{
 		RestApiId: aws.String(restId),
 	}
@@ -540,8 +1260,327 @@ func (a *APIGatewayRequest) GetStages(restId string) []*apigateway.Stage {
 	result, err := a.svc.GetStages(input)
 	if err != nil {
 		a.errorLog(err, apigateway.ErrCodeNotFoundException)
-		return make([]*apigateway.Stage, 0)
+		return nil, err
+	}
+	debugRequest(result)
+	return result.Item, nil
+}
+
+// GetApiKeyValue gets the value of api key.
+func (a *APIGatewayRequest) GetApiKeyValue(keyId string) (string, error) {
+	input := &apigateway.GetApiKeyInput{
+		ApiKey:       aws.String(keyId),
+		IncludeValue: aws.Bool(true),
+	}
+	debugRequest(input)
+	result, err := a.svc.GetApiKey(input)
+	if err != nil {
+		a.errorLog(err, apigateway.ErrCodeNotFoundException)
+		return "", err
+	}
+	return aws.StringValue(result.Value), nil
+}
+
+func (a *APIGatewayRequest) ApiKeyExists(keyId string) bool {
+	input := &apigateway.GetApiKeyInput{
+		ApiKey: aws.String(keyId),
+	}
+	debugRequest(input)
+	result, err := a.svc.GetApiKey(input)
+	if err != nil {
+		a.errorLog(err, apigateway.ErrCodeNotFoundException)
+		return false
 	}
 	debugRequest(result)
-	return result.Item
+	return true
+}
+
+// PutApiKey creates api key if it hasn't been created, otherwise updates enabled state.
+func (a *APIGatewayRequest) PutApiKey(k *entity.ApiKey) error {
+	if k.Id != "" && a.ApiKeyExists(k.Id) {
+		return a.updateApiKey(k)
+	}
+	return a.createApiKey(k)
+}
+
+func (a *APIGatewayRequest) createApiKey(k *entity.ApiKey) error {
+	a.log.Printf("Creating API key \"%s\"...\n", k.Name)
+	input := &apigateway.CreateApiKeyInput{
+		Name:        aws.String(k.Name),
+		Description: aws.String("Created via ginger project"),
+		Enabled:     aws.Bool(k.Enabled),
+	}
+	debugRequest(input)
+	result, err := a.svc.CreateApiKey(input)
+	if err != nil {
+		a.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	k.Id = *result.Id
+	a.log.Infof("API key created successfully. Id is %s\n", k.Id)
+	return nil
+}
+
+func (a *APIGatewayRequest) updateApiKey(k *entity.ApiKey) error {
+	a.log.Printf("Updating API key \"%s\"...\n", k.Name)
+	input := &apigateway.UpdateApiKeyInput{
+		ApiKey: aws.String(k.Id),
+		PatchOperations: []*apigateway.PatchOperation{
+			&apigateway.PatchOperation{
+				Op:    aws.String("replace"),
+				Path:  aws.String("/enabled"),
+				Value: aws.String(fmt.Sprint(k.Enabled)),
+			},
+		},
+	}
+	debugRequest(input)
+	result, err := a.svc.UpdateApiKey(input)
+	if err != nil {
+		a.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	a.log.Info("API key updated successfully.")
+	return nil
+}
+
+func (a *APIGatewayRequest) DeleteApiKey(keyId string) error {
+	a.log.Print("Deleting API key...")
+	input := &apigateway.DeleteApiKeyInput{
+		ApiKey: aws.String(keyId),
+	}
+	debugRequest(input)
+	result, err := a.svc.DeleteApiKey(input)
+	if err != nil {
+		a.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	a.log.Info("API key deleted successfully.")
+	return nil
+}
+
+func (a *APIGatewayRequest) GetUsagePlan(planId string) (*apigateway.UsagePlan, error) {
+	input := &apigateway.GetUsagePlanInput{
+		UsagePlanId: aws.String(planId),
+	}
+	debugRequest(input)
+	result, err := a.svc.GetUsagePlan(input)
+	if err != nil {
+		a.errorLog(err, apigateway.ErrCodeNotFoundException)
+		return nil, err
+	}
+	debugRequest(result)
+	return result, nil
+}
+
+// PutUsagePlan creates or updates usage plan.
+// stages should be filtered to the stages which exist in REST API because API Gateway rejects unknown stage.
+func (a *APIGatewayRequest) PutUsagePlan(restId string, u *entity.UsagePlan, stages []string) error {
+	if u.Id != "" {
+		if plan, err := a.GetUsagePlan(u.Id); err == nil {
+			return a.updateUsagePlan(restId, u, stages, plan)
+		}
+	}
+	return a.createUsagePlan(restId, u, stages)
+}
+
+func (a *APIGatewayRequest) createUsagePlan(restId string, u *entity.UsagePlan, stages []string) error {
+	a.log.Printf("Creating usage plan \"%s\"...\n", u.Name)
+	input := &apigateway.CreateUsagePlanInput{
+		Name: aws.String(u.Name),
+	}
+	if u.Description != "" {
+		input.Description = aws.String(u.Description)
+	}
+	for _, stage := range stages {
+		input.ApiStages = append(input.ApiStages, &apigateway.ApiStage{
+			ApiId: aws.String(restId),
+			Stage: aws.String(stage),
+		})
+	}
+	if u.Throttle != nil {
+		input.Throttle = &apigateway.ThrottleSettings{
+			BurstLimit: u.Throttle.BurstLimit,
+			RateLimit:  u.Throttle.RateLimit,
+		}
+	}
+	if u.Quota != nil {
+		input.Quota = &apigateway.QuotaSettings{
+			Limit:  aws.Int64(u.Quota.Limit),
+			Offset: aws.Int64(u.Quota.Offset),
+			Period: aws.String(strings.ToUpper(u.Quota.Period)),
+		}
+	}
+	debugRequest(input)
+	result, err := a.svc.CreateUsagePlan(input)
+	if err != nil {
+		a.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	u.Id = *result.Id
+	a.log.Infof("Usage plan created successfully. Id is %s\n", u.Id)
+	return nil
+}
+
+func (a *APIGatewayRequest) updateUsagePlan(restId string, u *entity.UsagePlan, stages []string, plan *apigateway.UsagePlan) error {
+	a.log.Printf("Updating usage plan \"%s\"...\n", u.Name)
+	operations := []*apigateway.PatchOperation{
+		&apigateway.PatchOperation{
+			Op:    aws.String("replace"),
+			Path:  aws.String("/description"),
+			Value: aws.String(u.Description),
+		},
+	}
+	if u.Throttle != nil {
+		if u.Throttle.BurstLimit != nil {
+			operations = append(operations, &apigateway.PatchOperation{
+				Op:    aws.String("replace"),
+				Path:  aws.String("/throttle/burstLimit"),
+				Value: aws.String(fmt.Sprint(*u.Throttle.BurstLimit)),
+			})
+		}
+		if u.Throttle.RateLimit != nil {
+			operations = append(operations, &apigateway.PatchOperation{
+				Op:    aws.String("replace"),
+				Path:  aws.String("/throttle/rateLimit"),
+				Value: aws.String(fmt.Sprint(*u.Throttle.RateLimit)),
+			})
+		}
+	} else if plan.Throttle != nil {
+		operations = append(operations, &apigateway.PatchOperation{
+			Op:   aws.String("remove"),
+			Path: aws.String("/throttle"),
+		})
+	}
+	if u.Quota != nil {
+		operations = append(operations, &apigateway.PatchOperation{
+			Op:    aws.String("replace"),
+			Path:  aws.String("/quota/limit"),
+			Value: aws.String(fmt.Sprint(u.Quota.Limit)),
+		}, &apigateway.PatchOperation{
+			Op:    aws.String("replace"),
+			Path:  aws.String("/quota/period"),
+			Value: aws.String(strings.ToUpper(u.Quota.Period)),
+		}, &apigateway.PatchOperation{
+			Op:    aws.String("replace"),
+			Path:  aws.String("/quota/offset"),
+			Value: aws.String(fmt.Sprint(u.Quota.Offset)),
+		})
+	} else if plan.Quota != nil {
+		operations = append(operations, &apigateway.PatchOperation{
+			Op:   aws.String("remove"),
+			Path: aws.String("/quota"),
+		})
+	}
+
+	// Sync api stages which belong to our REST API
+	attached := map[string]bool{}
+	for _, s := range plan.ApiStages {
+		if aws.StringValue(s.ApiId) == restId {
+			attached[aws.StringValue(s.Stage)] = true
+		}
+	}
+	for _, stage := range stages {
+		if _, ok := attached[stage]; ok {
+			delete(attached, stage)
+			continue
+		}
+		operations = append(operations, &apigateway.PatchOperation{
+			Op:    aws.String("add"),
+			Path:  aws.String("/apiStages"),
+			Value: aws.String(restId + ":" + stage),
+		})
+	}
+	for stage := range attached {
+		operations = append(operations, &apigateway.PatchOperation{
+			Op:    aws.String("remove"),
+			Path:  aws.String("/apiStages"),
+			Value: aws.String(restId + ":" + stage),
+		})
+	}
+
+	input := &apigateway.UpdateUsagePlanInput{
+		UsagePlanId:     aws.String(u.Id),
+		PatchOperations: operations,
+	}
+	debugRequest(input)
+	result, err := a.svc.UpdateUsagePlan(input)
+	if err != nil {
+		a.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	a.log.Info("Usage plan updated successfully.")
+	return nil
+}
+
+// GetUsagePlanKeys gets key ids which are attached to usage plan.
+func (a *APIGatewayRequest) GetUsagePlanKeys(planId string) ([]string, error) {
+	input := &apigateway.GetUsagePlanKeysInput{
+		UsagePlanId: aws.String(planId),
+		Limit:       aws.Int64(500),
+	}
+	debugRequest(input)
+	keys := []string{}
+	err := a.svc.GetUsagePlanKeysPages(input, func(page *apigateway.GetUsagePlanKeysOutput, lastPage bool) bool {
+		debugRequest(page)
+		for _, k := range page.Items {
+			keys = append(keys, aws.StringValue(k.Id))
+		}
+		return !lastPage
+	})
+	if err != nil {
+		a.errorLog(err)
+		return nil, err
+	}
+	return keys, nil
+}
+
+// SyncUsagePlanKeys attaches supplied api keys to usage plan, and detaches keys which aren't supplied.
+func (a *APIGatewayRequest) SyncUsagePlanKeys(planId string, keyIds []string) error {
+	a.log.Print("Syncing usage plan keys...")
+	remote, err := a.GetUsagePlanKeys(planId)
+	if err != nil {
+		return err
+	}
+	attached := map[string]bool{}
+	for _, id := range remote {
+		attached[id] = true
+	}
+	for _, id := range keyIds {
+		if _, ok := attached[id]; ok {
+			delete(attached, id)
+			continue
+		}
+		input := &apigateway.CreateUsagePlanKeyInput{
+			KeyId:       aws.String(id),
+			KeyType:     aws.String("API_KEY"),
+			UsagePlanId: aws.String(planId),
+		}
+		debugRequest(input)
+		result, err := a.svc.CreateUsagePlanKey(input)
+		if err != nil {
+			a.errorLog(err)
+			return err
+		}
+		debugRequest(result)
+	}
+	for id := range attached {
+		input := &apigateway.DeleteUsagePlanKeyInput{
+			KeyId:       aws.String(id),
+			UsagePlanId: aws.String(planId),
+		}
+		debugRequest(input)
+		result, err := a.svc.DeleteUsagePlanKey(input)
+		if err != nil {
+			a.errorLog(err)
+			return err
+		}
+		debugRequest(result)
+	}
+	a.log.Info("Usage plan keys synced successfully.")
+	return nil
 }
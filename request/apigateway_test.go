@@ -0,0 +1,171 @@
+package request
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/apigateway"
+
+	"github.com/ysugimoto/ginger/entity"
+)
+
+func TestFormatMethodSettingPath(t *testing.T) {
+	tests := []struct {
+		key    string
+		expect string
+	}{
+		{key: "users/GET", expect: "/~1users/GET"},
+		{key: "/users/get", expect: "/~1users/GET"},
+		{key: "users/{id}/POST", expect: "/~1users~1{id}/POST"},
+		{key: "/GET", expect: "/~1/GET"},
+		{key: "GET", expect: "/~1/GET"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if actual := formatMethodSettingPath(tt.key); actual != tt.expect {
+				t.Errorf("expected %s, got %s", tt.expect, actual)
+			}
+		})
+	}
+}
+
+func TestMethodSettingKeyPath(t *testing.T) {
+	tests := []struct {
+		key    string
+		expect string
+	}{
+		{key: "*/*", expect: "/*/*"},
+		{key: "~1users/GET", expect: "/~1users/GET"},
+		{key: "~1users~1{id}/POST", expect: "/~1users~1{id}/POST"},
+		{key: "users/GET", expect: "/~1users/GET"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if actual := methodSettingKeyPath(tt.key); actual != tt.expect {
+				t.Errorf("expected %s, got %s", tt.expect, actual)
+			}
+		})
+	}
+}
+
+func TestIsThrottlingOverride(t *testing.T) {
+	defaults := &apigateway.MethodSetting{
+		LoggingLevel:         aws.String("OFF"),
+		CachingEnabled:       aws.Bool(false),
+		ThrottlingBurstLimit: aws.Int64(100),
+		ThrottlingRateLimit:  aws.Float64(50),
+	}
+	tests := []struct {
+		name    string
+		setting *apigateway.MethodSetting
+		expect  bool
+	}{
+		{
+			name: "throttling is overridden",
+			setting: &apigateway.MethodSetting{
+				LoggingLevel:         aws.String("OFF"),
+				CachingEnabled:       aws.Bool(false),
+				ThrottlingBurstLimit: aws.Int64(10),
+				ThrottlingRateLimit:  aws.Float64(5),
+			},
+			expect: true,
+		},
+		{
+			name: "logging is overridden",
+			setting: &apigateway.MethodSetting{
+				LoggingLevel:         aws.String("INFO"),
+				CachingEnabled:       aws.Bool(false),
+				ThrottlingBurstLimit: aws.Int64(10),
+				ThrottlingRateLimit:  aws.Float64(5),
+			},
+		},
+		{
+			name: "caching is overridden",
+			setting: &apigateway.MethodSetting{
+				LoggingLevel:         aws.String("OFF"),
+				CachingEnabled:       aws.Bool(true),
+				ThrottlingBurstLimit: aws.Int64(100),
+				ThrottlingRateLimit:  aws.Float64(50),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := isThrottlingOverride(tt.setting, defaults); actual != tt.expect {
+				t.Errorf("expected %t, got %t", tt.expect, actual)
+			}
+		})
+	}
+	if isThrottlingOverride(tests[0].setting, nil) {
+		t.Errorf("expected false without stage's default setting")
+	}
+}
+
+func TestAuthorizerPatchOperations(t *testing.T) {
+	format := func(operations []*apigateway.PatchOperation) []string {
+		formatted := []string{}
+		for _, op := range operations {
+			formatted = append(formatted, aws.StringValue(op.Op)+" "+aws.StringValue(op.Path)+" "+aws.StringValue(op.Value))
+		}
+		sort.Strings(formatted)
+		return formatted
+	}
+	tests := []struct {
+		name    string
+		au      *entity.Authorizer
+		current *apigateway.Authorizer
+		uri     *string
+		expect  []string
+	}{
+		{
+			name:    "lambda authorizer",
+			au:      &entity.Authorizer{Name: "auth", Type: entity.AuthorizerToken, IdentitySource: "method.request.header.Authorization", TTL: 300},
+			current: &apigateway.Authorizer{},
+			uri:     aws.String("arn:aws:apigateway:us-east-1:lambda:path/functions/auth/invocations"),
+			expect: []string{
+				"replace /authorizerResultTtlInSeconds 300",
+				"replace /authorizerUri arn:aws:apigateway:us-east-1:lambda:path/functions/auth/invocations",
+				"replace /identitySource method.request.header.Authorization",
+				"replace /name auth",
+			},
+		},
+		{
+			name: "empty identity source is left as it is",
+			au:   &entity.Authorizer{Name: "auth", Type: entity.AuthorizerRequest, TTL: 0},
+			current: &apigateway.Authorizer{
+				IdentitySource: aws.String("method.request.header.Authorization"),
+			},
+			expect: []string{
+				"replace /authorizerResultTtlInSeconds 0",
+				"replace /name auth",
+			},
+		},
+		{
+			name: "provider ARNs are synced",
+			au:   &entity.Authorizer{Name: "cognito", Type: entity.AuthorizerCognito, ProviderArns: []string{"arn:pool:a", "arn:pool:c"}, TTL: 300},
+			current: &apigateway.Authorizer{
+				ProviderARNs: aws.StringSlice([]string{"arn:pool:a", "arn:pool:b"}),
+			},
+			expect: []string{
+				"add /providerARNs arn:pool:c",
+				"remove /providerARNs arn:pool:b",
+				"replace /authorizerResultTtlInSeconds 300",
+				"replace /name cognito",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := format(authorizerPatchOperations(tt.au, tt.current, tt.uri))
+			if !reflect.DeepEqual(actual, tt.expect) {
+				t.Errorf("expected %v, got %v", tt.expect, actual)
+			}
+		})
+	}
+}
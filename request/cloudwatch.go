@@ -7,6 +7,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/cloudwatchevents"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 
@@ -17,19 +18,21 @@ import (
 
 // CloudWatchRequest is the struct which wrap AWS cloud watch logs service.
 type CloudWatchRequest struct {
-	svc    *cloudwatchlogs.CloudWatchLogs
-	events *cloudwatchevents.CloudWatchEvents
-	log    *logger.Logger
-	config *config.Config
+	svc     *cloudwatchlogs.CloudWatchLogs
+	events  *cloudwatchevents.CloudWatchEvents
+	metrics *cloudwatch.CloudWatch
+	log     *logger.Logger
+	config  *config.Config
 }
 
 func NewCloudWatch(c *config.Config) *CloudWatchRequest {
 	sess := createAWSSession(c)
 	return &CloudWatchRequest{
-		config: c,
-		svc:    cloudwatchlogs.New(sess),
-		events: cloudwatchevents.New(sess),
-		log:    logger.WithNamespace("ginger.request.cloudwatch"),
+		config:  c,
+		svc:     cloudwatchlogs.New(sess),
+		events:  cloudwatchevents.New(sess),
+		metrics: cloudwatch.New(sess),
+		log:     logger.WithNamespace("ginger.request.cloudwatch"),
 	}
 }
 
@@ -156,6 +159,38 @@ func (c *CloudWatchRequest) GetScheduleArn(name string) (string, error) {
 	return "", nil
 }
 
+// DescribeSchedule gets schedule rule detail by name.
+func (c *CloudWatchRequest) DescribeSchedule(name string) (*cloudwatchevents.DescribeRuleOutput, error) {
+	input := &cloudwatchevents.DescribeRuleInput{
+		Name: aws.String(name),
+	}
+	debugRequest(input)
+	result, err := c.events.DescribeRule(input)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != cloudwatchevents.ErrCodeResourceNotFoundException {
+			c.errorLog(err)
+		}
+		return nil, err
+	}
+	debugRequest(result)
+	return result, nil
+}
+
+// ListTargets lists targets which are attached to schedule rule.
+func (c *CloudWatchRequest) ListTargets(scheduleName string) ([]*cloudwatchevents.Target, error) {
+	input := &cloudwatchevents.ListTargetsByRuleInput{
+		Rule: aws.String(scheduleName),
+	}
+	debugRequest(input)
+	result, err := c.events.ListTargetsByRule(input)
+	if err != nil {
+		c.errorLog(err)
+		return nil, err
+	}
+	debugRequest(result)
+	return result.Targets, nil
+}
+
 func (c *CloudWatchRequest) DeleteSchedule(name string) error {
 	c.log.Printf("Delete schedule from cloudwatch, name %s...\n", name)
 	input := &cloudwatchevents.DeleteRuleInput{
@@ -193,3 +228,55 @@ func (c *CloudWatchRequest) PutTarget(scheduleName string, functionArn *string)
 	c.log.Info("Put schedule target successfully")
 	return nil
 }
+
+// SumFunctionMetric sums Lambda metric of the version which is invoked via alias.
+func (c *CloudWatchRequest) SumFunctionMetric(name, alias, version, metricName string, start, end time.Time) (float64, error) {
+	input := &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String("AWS/Lambda"),
+		MetricName: aws.String(metricName),
+		Dimensions: []*cloudwatch.Dimension{
+			{Name: aws.String("FunctionName"), Value: aws.String(name)},
+			{Name: aws.String("Resource"), Value: aws.String(name + ":" + alias)},
+			{Name: aws.String("ExecutedVersion"), Value: aws.String(version)},
+		},
+		StartTime:  aws.Time(start),
+		EndTime:    aws.Time(end),
+		Period:     aws.Int64(60),
+		Statistics: []*string{aws.String(cloudwatch.StatisticSum)},
+	}
+	debugRequest(input)
+	result, err := c.metrics.GetMetricStatistics(input)
+	if err != nil {
+		c.errorLog(err)
+		return 0, err
+	}
+	debugRequest(result)
+	var sum float64
+	for _, p := range result.Datapoints {
+		sum += aws.Float64Value(p.Sum)
+	}
+	return sum, nil
+}
+
+// AlarmingAlarms returns alarm names which are in ALARM state.
+func (c *CloudWatchRequest) AlarmingAlarms(names []string) ([]string, error) {
+	input := &cloudwatch.DescribeAlarmsInput{
+		AlarmNames: aws.StringSlice(names),
+		StateValue: aws.String(cloudwatch.StateValueAlarm),
+	}
+	debugRequest(input)
+	result, err := c.metrics.DescribeAlarms(input)
+	if err != nil {
+		c.errorLog(err)
+		return nil, err
+	}
+	debugRequest(result)
+	alarming := []string{}
+	for _, a := range result.MetricAlarms {
+		alarming = append(alarming, aws.StringValue(a.AlarmName))
+	}
+	for _, a := range result.CompositeAlarms {
+		alarming = append(alarming, aws.StringValue(a.AlarmName))
+	}
+	return alarming, nil
+}
@@ -2,6 +2,10 @@ package request
 
 import (
 	"fmt"
+	"strings"
+
+	"crypto/sha256"
+	"encoding/base64"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -105,7 +109,9 @@ func (l *LambdaRequest) DeleteFunction(name string) error {
 	return nil
 }
 
-func (l *LambdaRequest) DeployFunction(fn *entity.Function, zipBytes []byte) (string, error) {
+// DeployFunction creates or updates function, and returns published version configuration and uploaded code location.
+// Code location is nil if code isn't uploaded because deployed code is identical.
+func (l *LambdaRequest) DeployFunction(fn *entity.Function, zipBytes []byte) (*lambda.FunctionConfiguration, *lambda.FunctionCode, error) {
 	if l.FunctionExists(fn.Name) {
 		l.log.Printf("%s already exists, update fucntion\n", fn.Name)
 		return l.UpdateFunction(fn, zipBytes)
@@ -115,24 +121,39 @@ func (l *LambdaRequest) DeployFunction(fn *entity.Function, zipBytes []byte) (st
 	}
 }
 
-func (l *LambdaRequest) CreateFunction(fn *entity.Function, zipBytes []byte) (string, error) {
+func (l *LambdaRequest) CreateFunction(fn *entity.Function, zipBytes []byte) (*lambda.FunctionConfiguration, *lambda.FunctionCode, error) {
+	code, err := l.functionCode(fn, zipBytes)
+	if err != nil {
+		return nil, nil, err
+	}
 	input := &lambda.CreateFunctionInput{
-		Code: &lambda.FunctionCode{
-			ZipFile: zipBytes,
-		},
-		FunctionName: aws.String(fn.Name),
-		Handler:      aws.String(fn.Name),
-		Role:         aws.String(fn.Role),
-		MemorySize:   aws.Int64(fn.MemorySize),
-		Publish:      aws.Bool(true),
-		Runtime:      aws.String("go1.x"),
-		Timeout:      aws.Int64(fn.Timeout),
+		Code:          code,
+		FunctionName:  aws.String(fn.Name),
+		Handler:       aws.String(fn.Handler()),
+		Role:          aws.String(fn.Role),
+		MemorySize:    aws.Int64(fn.MemorySize),
+		Publish:       aws.Bool(true),
+		Runtime:       aws.String(fn.GetRuntime()),
+		Architectures: []*string{aws.String(fn.GetArchitecture())},
+		Timeout:       aws.Int64(fn.Timeout),
 	}
 	if fn.Environment != nil {
 		input = input.SetEnvironment(&lambda.Environment{
 			Variables: fn.Environment,
 		})
 	}
+	if dlq := fn.DeadLetterQueue(); dlq != "" {
+		input = input.SetDeadLetterConfig(&lambda.DeadLetterConfig{
+			TargetArn: aws.String(dlq),
+		})
+	}
+	if len(fn.Layers) > 0 {
+		layers, err := l.ResolveLayers(fn.Layers)
+		if err != nil {
+			return nil, nil, err
+		}
+		input = input.SetLayers(layers)
+	}
 	// Append VPC configuration if specified
 	if fn.VPC != nil {
 		sn := []*string{}
@@ -152,33 +173,148 @@ func (l *LambdaRequest) CreateFunction(fn *entity.Function, zipBytes []byte) (st
 	result, err := l.svc.CreateFunction(input)
 	if err != nil {
 		l.errorLog(err)
-		return "", err
+		return nil, nil, err
 	}
 	debugRequest(result)
-	return *result.FunctionArn, nil
+	return result, code, nil
 }
 
-func (l *LambdaRequest) UpdateFunction(fn *entity.Function, zipBytes []byte) (string, error) {
+func (l *LambdaRequest) UpdateFunction(fn *entity.Function, zipBytes []byte) (*lambda.FunctionConfiguration, *lambda.FunctionCode, error) {
+	// Skip to upload if deployed code is identical, but configuration may be changed so publish version
+	if current, err := l.GetFunction(fn.Name); err == nil && current.CodeSha256 != nil {
+		sum := sha256.Sum256(zipBytes)
+		if *current.CodeSha256 == base64.StdEncoding.EncodeToString(sum[:]) {
+			l.log.Printf("Code of %s is not changed, skip to update code\n", fn.Name)
+			result, err := l.updateFunction(fn, nil)
+			if err != nil {
+				return nil, nil, err
+			}
+			return result, nil, nil
+		}
+	}
+	code, err := l.functionCode(fn, zipBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := l.updateFunction(fn, code)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, code, nil
+}
+
+// RedeployArtifact updates function code and configuration from the package which was uploaded to S3 before,
+// and returns published version configuration.
+func (l *LambdaRequest) RedeployArtifact(fn *entity.Function, bucket, key, objectVersion string) (*lambda.FunctionConfiguration, error) {
+	code := &lambda.FunctionCode{
+		S3Bucket: aws.String(bucket),
+		S3Key:    aws.String(key),
+	}
+	if objectVersion != "" {
+		code.S3ObjectVersion = aws.String(objectVersion)
+	}
+	return l.updateFunction(fn, code)
+}
+
+// updateFunction updates function code if supplied, then configuration, and publishes new version.
+// Code is updated first and configuration waits for it, because runtime and handler must not be switched
+// while old package is still deployed, e.g. on migration from go1.x to provided runtime.
+func (l *LambdaRequest) updateFunction(fn *entity.Function, code *lambda.FunctionCode) (*lambda.FunctionConfiguration, error) {
+	if code != nil {
+		// Configuration cannot be updated while code update is in progress
+		if err := l.updateFunctionCode(fn, code); err != nil {
+			return nil, err
+		}
+		if err := l.WaitFunctionUpdated(fn.Name); err != nil {
+			return nil, err
+		}
+	}
 	if err := l.UpdateFunctionConfiguration(fn); err != nil {
-		return "", err
+		return nil, err
 	}
+	return l.PublishVersion(fn.Name)
+}
+
+// updateFunctionCode updates function code without publishing version.
+func (l *LambdaRequest) updateFunctionCode(fn *entity.Function, code *lambda.FunctionCode) error {
+	// Architecture is changed with code, not configuration
 	input := &lambda.UpdateFunctionCodeInput{
-		FunctionName: aws.String(fn.Name),
-		Publish:      aws.Bool(true),
-		ZipFile:      zipBytes,
+		FunctionName:    aws.String(fn.Name),
+		ZipFile:         code.ZipFile,
+		S3Bucket:        code.S3Bucket,
+		S3Key:           code.S3Key,
+		S3ObjectVersion: code.S3ObjectVersion,
+		Architectures:   []*string{aws.String(fn.GetArchitecture())},
 	}
 
 	debugRequest(input)
 	result, err := l.svc.UpdateFunctionCode(input)
 	if err != nil {
 		l.errorLog(err)
-		return "", err
+		return err
 	}
 	debugRequest(result)
-	return *result.FunctionArn, nil
+	return nil
+}
+
+// PublishVersion publishes current code and configuration as new version.
+// If nothing is changed since last published version, AWS returns the last version.
+func (l *LambdaRequest) PublishVersion(name string) (*lambda.FunctionConfiguration, error) {
+	if err := l.WaitFunctionUpdated(name); err != nil {
+		return nil, err
+	}
+	input := &lambda.PublishVersionInput{
+		FunctionName: aws.String(name),
+	}
+	debugRequest(input)
+	result, err := l.svc.PublishVersion(input)
+	if err != nil {
+		l.errorLog(err)
+		return nil, err
+	}
+	debugRequest(result)
+	return result, nil
+}
+
+// functionCode makes code location of the function package.
+// If package should be uploaded via S3, upload zip to project bucket under versioned key and refer it,
+// otherwise send zip inline.
+func (l *LambdaRequest) functionCode(fn *entity.Function, zipBytes []byte) (*lambda.FunctionCode, error) {
+	if !l.config.UploadCodeViaS3(len(zipBytes)) {
+		return &lambda.FunctionCode{
+			ZipFile: zipBytes,
+		}, nil
+	}
+	return l.uploadArtifact(fn.Name, config.FunctionArtifactKey(fn.Name, zipBytes), zipBytes)
+}
+
+// uploadArtifact uploads package zip to project bucket, and returns its code location.
+func (l *LambdaRequest) uploadArtifact(name, key string, zipBytes []byte) (*lambda.FunctionCode, error) {
+	bucket := l.config.S3BucketName
+	if bucket == "" {
+		return nil, fmt.Errorf("S3 bucket name is empty. Please set bucket by `ginger config --bucket` to upload package via S3")
+	}
+	s3 := NewS3(l.config)
+	if err := s3.EnsureBucketExists(bucket); err != nil {
+		return nil, err
+	}
+	l.log.Printf("Uploading package of %s to s3://%s/%s...\n", name, bucket, key)
+	version, err := s3.PutFunctionArtifact(bucket, key, zipBytes)
+	if err != nil {
+		return nil, err
+	}
+	code := &lambda.FunctionCode{
+		S3Bucket: aws.String(bucket),
+		S3Key:    aws.String(key),
+	}
+	if version != "" {
+		code.S3ObjectVersion = aws.String(version)
+	}
+	return code, nil
 }
 
 func (l *LambdaRequest) AddS3Permission(name, bucketName string) error {
+	function, qualifier := entity.SplitQualifier(name)
 	l.log.Printf("Add S3 permission for %s...\n", name)
 	sts := NewSts(l.config)
 	account, err := sts.GetAccount()
@@ -190,9 +326,12 @@ func (l *LambdaRequest) AddS3Permission(name, bucketName string) error {
 		Principal:     aws.String("s3.amazonaws.com"),
 		SourceArn:     aws.String(fmt.Sprintf("arn:aws:s3:::%s", bucketName)),
 		SourceAccount: aws.String(account),
-		FunctionName:  aws.String(name),
+		FunctionName:  aws.String(function),
 		StatementId:   aws.String(generateStatementId("s3")),
 	}
+	if qualifier != "" {
+		input = input.SetQualifier(qualifier)
+	}
 	debugRequest(input)
 	result, err := l.svc.AddPermission(input)
 	if err != nil {
@@ -205,17 +344,51 @@ func (l *LambdaRequest) AddS3Permission(name, bucketName string) error {
 }
 
 func (l *LambdaRequest) AddAPIGatewayPermission(name, apiArn string) error {
+	function, qualifier := entity.SplitQualifier(name)
 	l.log.Printf("Add API Gateway permission for %s...\n", name)
 	input := &lambda.AddPermissionInput{
 		Action:       aws.String("lambda:InvokeFunction"),
 		Principal:    aws.String("apigateway.amazonaws.com"),
 		SourceArn:    aws.String(apiArn),
-		FunctionName: aws.String(name),
+		FunctionName: aws.String(function),
 		StatementId:  aws.String(generateStatementId("apigateway")),
 	}
+	if qualifier != "" {
+		input = input.SetQualifier(qualifier)
+	}
+	debugRequest(input)
+	result, err := l.svc.AddPermission(input)
+	if err != nil {
+		l.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	l.log.Info("Permission added successfully")
+	return nil
+}
+
+// PutAPIGatewayPermission adds API Gateway permission with fixed statement id.
+// Unlike AddAPIGatewayPermission, it's safe to call on every deployment because existing statement is kept.
+func (l *LambdaRequest) PutAPIGatewayPermission(name, apiArn, statementId string) error {
+	function, qualifier := entity.SplitQualifier(name)
+	l.log.Printf("Put API Gateway permission for %s...\n", name)
+	input := &lambda.AddPermissionInput{
+		Action:       aws.String("lambda:InvokeFunction"),
+		Principal:    aws.String("apigateway.amazonaws.com"),
+		SourceArn:    aws.String(apiArn),
+		FunctionName: aws.String(function),
+		StatementId:  aws.String(statementId),
+	}
+	if qualifier != "" {
+		input = input.SetQualifier(qualifier)
+	}
 	debugRequest(input)
 	result, err := l.svc.AddPermission(input)
 	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == lambda.ErrCodeResourceConflictException {
+			l.log.Info("Permission already exists")
+			return nil
+		}
 		l.errorLog(err)
 		return err
 	}
@@ -225,14 +398,18 @@ func (l *LambdaRequest) AddAPIGatewayPermission(name, apiArn string) error {
 }
 
 func (l *LambdaRequest) AddCloudWatchPermission(name, eventArn string) error {
+	function, qualifier := entity.SplitQualifier(name)
 	l.log.Printf("Add CloudWatch permission for %s...\n", name)
 	input := &lambda.AddPermissionInput{
 		Action:       aws.String("lambda:InvokeFunction"),
 		Principal:    aws.String("events.amazonaws.com"),
-		FunctionName: aws.String(name),
+		FunctionName: aws.String(function),
 		StatementId:  aws.String(generateStatementId("cloudwatch")),
 		SourceArn:    aws.String(eventArn),
 	}
+	if qualifier != "" {
+		input = input.SetQualifier(qualifier)
+	}
 	debugRequest(input)
 	result, err := l.svc.AddPermission(input)
 	if err != nil {
@@ -263,7 +440,9 @@ func (l *LambdaRequest) UpdateFunctionConfiguration(fn *entity.Function) error {
 	l.log.Printf("Updating function configuration for %s...\n", fn.Name)
 	input := &lambda.UpdateFunctionConfigurationInput{
 		FunctionName: aws.String(fn.Name),
+		Handler:      aws.String(fn.Handler()),
 		MemorySize:   aws.Int64(fn.MemorySize),
+		Runtime:      aws.String(fn.GetRuntime()),
 		Timeout:      aws.Int64(fn.Timeout),
 	}
 	// Append VPC configuration if specified
@@ -286,6 +465,16 @@ func (l *LambdaRequest) UpdateFunctionConfiguration(fn *entity.Function) error {
 			Variables: fn.Environment,
 		})
 	}
+	// Empty target removes dead letter queue
+	input = input.SetDeadLetterConfig(&lambda.DeadLetterConfig{
+		TargetArn: aws.String(fn.DeadLetterQueue()),
+	})
+	// Empty list removes all layers
+	layers, err := l.ResolveLayers(fn.Layers)
+	if err != nil {
+		return err
+	}
+	input = input.SetLayers(layers)
 	debugRequest(input)
 	result, err := l.svc.UpdateFunctionConfiguration(input)
 	if err != nil {
@@ -297,6 +486,381 @@ func (l *LambdaRequest) UpdateFunctionConfiguration(fn *entity.Function) error {
 	return nil
 }
 
+// PublishLayerVersion publishes layer package as new layer version.
+// If package should be uploaded via S3, upload zip to project bucket and refer it.
+func (l *LambdaRequest) PublishLayerVersion(layer *entity.Layer, zipBytes []byte) (*lambda.PublishLayerVersionOutput, error) {
+	content := &lambda.LayerVersionContentInput{}
+	if l.config.UploadCodeViaS3(len(zipBytes)) {
+		code, err := l.uploadArtifact(layer.Name, config.LayerArtifactKey(layer.Name, zipBytes), zipBytes)
+		if err != nil {
+			return nil, err
+		}
+		content.S3Bucket = code.S3Bucket
+		content.S3Key = code.S3Key
+		content.S3ObjectVersion = code.S3ObjectVersion
+	} else {
+		content.ZipFile = zipBytes
+	}
+	input := &lambda.PublishLayerVersionInput{
+		LayerName:               aws.String(layer.Name),
+		Content:                 content,
+		CompatibleRuntimes:      aws.StringSlice(layer.CompatibleRuntimes),
+		CompatibleArchitectures: aws.StringSlice(layer.CompatibleArchitectures),
+	}
+	if layer.Description != "" {
+		input = input.SetDescription(layer.Description)
+	}
+	debugRequest(input)
+	result, err := l.svc.PublishLayerVersion(input)
+	if err != nil {
+		l.errorLog(err)
+		return nil, err
+	}
+	debugRequest(result)
+	return result, nil
+}
+
+// GetLatestLayerVersion returns the latest published version of layer.
+func (l *LambdaRequest) GetLatestLayerVersion(name string) (*lambda.LayerVersionsListItem, error) {
+	input := &lambda.ListLayerVersionsInput{
+		LayerName: aws.String(name),
+		MaxItems:  aws.Int64(1),
+	}
+	debugRequest(input)
+	result, err := l.svc.ListLayerVersions(input)
+	if err != nil {
+		l.errorLog(err)
+		return nil, err
+	}
+	debugRequest(result)
+	if len(result.LayerVersions) == 0 {
+		return nil, fmt.Errorf("Layer %s has not been published yet", name)
+	}
+	return result.LayerVersions[0], nil
+}
+
+// ResolveLayers returns layer version arns of layer references.
+// Layer version arn is used as it is, and layer name is resolved to the latest published version.
+func (l *LambdaRequest) ResolveLayers(refs []string) ([]*string, error) {
+	layers := []*string{}
+	for _, ref := range refs {
+		if strings.HasPrefix(ref, "arn:") {
+			layers = append(layers, aws.String(ref))
+			continue
+		}
+		version, err := l.GetLatestLayerVersion(ref)
+		if err != nil {
+			return nil, err
+		}
+		l.log.Printf("Layer %s is resolved to version %d\n", ref, aws.Int64Value(version.Version))
+		layers = append(layers, version.LayerVersionArn)
+	}
+	return layers, nil
+}
+
+// ResolveFunctionArn returns arn of function reference.
+// If reference is qualified by alias like "name:alias", returns alias arn.
+func (l *LambdaRequest) ResolveFunctionArn(ref string) (*string, error) {
+	name, qualifier := entity.SplitQualifier(ref)
+	if qualifier == "" {
+		fn, err := l.GetFunction(name)
+		if err != nil {
+			return nil, err
+		}
+		return fn.FunctionArn, nil
+	}
+	alias, err := l.GetAlias(name, qualifier)
+	if err != nil {
+		return nil, err
+	}
+	return alias.AliasArn, nil
+}
+
+// GetAlias gets alias of function.
+func (l *LambdaRequest) GetAlias(name, alias string) (*lambda.AliasConfiguration, error) {
+	input := &lambda.GetAliasInput{
+		FunctionName: aws.String(name),
+		Name:         aws.String(alias),
+	}
+	debugRequest(input)
+	result, err := l.svc.GetAlias(input)
+	if err != nil {
+		l.errorLog(err, lambda.ErrCodeResourceNotFoundException)
+		return nil, err
+	}
+	debugRequest(result)
+	return result, nil
+}
+
+// PutAlias points alias to the version. Alias is created if not exists.
+func (l *LambdaRequest) PutAlias(name, alias, version string) error {
+	current, err := l.GetAlias(name, alias)
+	if err != nil {
+		l.log.Printf("Creating alias %s of %s for version %s...\n", alias, name, version)
+		input := &lambda.CreateAliasInput{
+			FunctionName:    aws.String(name),
+			Name:            aws.String(alias),
+			FunctionVersion: aws.String(version),
+		}
+		debugRequest(input)
+		result, err := l.svc.CreateAlias(input)
+		if err != nil {
+			l.errorLog(err)
+			return err
+		}
+		debugRequest(result)
+		return nil
+	} else if aws.StringValue(current.FunctionVersion) == version {
+		return nil
+	}
+	l.log.Printf("Updating alias %s of %s to version %s...\n", alias, name, version)
+	input := &lambda.UpdateAliasInput{
+		FunctionName:    aws.String(name),
+		Name:            aws.String(alias),
+		FunctionVersion: aws.String(version),
+	}
+	debugRequest(input)
+	result, err := l.svc.UpdateAlias(input)
+	if err != nil {
+		l.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	return nil
+}
+
+// RouteAlias points alias to the version and routes traffic to additional version by weight.
+// Weight is between 0.0 and 1.0, and routing is cleared if additional version is empty.
+func (l *LambdaRequest) RouteAlias(name, alias, version, additionalVersion string, weight float64) error {
+	weights := map[string]*float64{}
+	if additionalVersion != "" {
+		weights[additionalVersion] = aws.Float64(weight)
+	}
+	input := &lambda.UpdateAliasInput{
+		FunctionName:    aws.String(name),
+		Name:            aws.String(alias),
+		FunctionVersion: aws.String(version),
+		RoutingConfig: &lambda.AliasRoutingConfiguration{
+			AdditionalVersionWeights: weights,
+		},
+	}
+	debugRequest(input)
+	result, err := l.svc.UpdateAlias(input)
+	if err != nil {
+		l.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	return nil
+}
+
+// GetReservedConcurrency gets reserved concurrency of function, or nil if unreserved.
+func (l *LambdaRequest) GetReservedConcurrency(name string) (*int64, error) {
+	input := &lambda.GetFunctionConcurrencyInput{
+		FunctionName: aws.String(name),
+	}
+	debugRequest(input)
+	result, err := l.svc.GetFunctionConcurrency(input)
+	if err != nil {
+		l.errorLog(err)
+		return nil, err
+	}
+	debugRequest(result)
+	return result.ReservedConcurrentExecutions, nil
+}
+
+// PutReservedConcurrency reserves concurrency for function.
+func (l *LambdaRequest) PutReservedConcurrency(name string, concurrency int64) error {
+	l.log.Printf("Putting reserved concurrency %d for %s...\n", concurrency, name)
+	input := &lambda.PutFunctionConcurrencyInput{
+		FunctionName:                 aws.String(name),
+		ReservedConcurrentExecutions: aws.Int64(concurrency),
+	}
+	debugRequest(input)
+	result, err := l.svc.PutFunctionConcurrency(input)
+	if err != nil {
+		l.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	return nil
+}
+
+// DeleteReservedConcurrency removes reserved concurrency from function.
+func (l *LambdaRequest) DeleteReservedConcurrency(name string) error {
+	l.log.Printf("Deleting reserved concurrency for %s...\n", name)
+	input := &lambda.DeleteFunctionConcurrencyInput{
+		FunctionName: aws.String(name),
+	}
+	debugRequest(input)
+	result, err := l.svc.DeleteFunctionConcurrency(input)
+	if err != nil {
+		l.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	return nil
+}
+
+// ListProvisionedConcurrency lists requested provisioned concurrency of function, keyed by qualifier.
+func (l *LambdaRequest) ListProvisionedConcurrency(name string) (map[string]int64, error) {
+	input := &lambda.ListProvisionedConcurrencyConfigsInput{
+		FunctionName: aws.String(name),
+	}
+	debugRequest(input)
+	configs := map[string]int64{}
+	err := l.svc.ListProvisionedConcurrencyConfigsPages(input, func(page *lambda.ListProvisionedConcurrencyConfigsOutput, lastPage bool) bool {
+		debugRequest(page)
+		for _, config := range page.ProvisionedConcurrencyConfigs {
+			arn := aws.StringValue(config.FunctionArn)
+			qualifier := arn[strings.LastIndex(arn, ":")+1:]
+			configs[qualifier] = aws.Int64Value(config.RequestedProvisionedConcurrentExecutions)
+		}
+		return !lastPage
+	})
+	if err != nil {
+		l.errorLog(err)
+		return nil, err
+	}
+	return configs, nil
+}
+
+// PutProvisionedConcurrency allocates provisioned concurrency for alias.
+func (l *LambdaRequest) PutProvisionedConcurrency(name, alias string, concurrency int64) error {
+	l.log.Printf("Putting provisioned concurrency %d for %s:%s...\n", concurrency, name, alias)
+	input := &lambda.PutProvisionedConcurrencyConfigInput{
+		FunctionName:                    aws.String(name),
+		Qualifier:                       aws.String(alias),
+		ProvisionedConcurrentExecutions: aws.Int64(concurrency),
+	}
+	debugRequest(input)
+	result, err := l.svc.PutProvisionedConcurrencyConfig(input)
+	if err != nil {
+		l.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	return nil
+}
+
+// DeleteProvisionedConcurrency removes provisioned concurrency from alias.
+func (l *LambdaRequest) DeleteProvisionedConcurrency(name, alias string) error {
+	l.log.Printf("Deleting provisioned concurrency for %s:%s...\n", name, alias)
+	input := &lambda.DeleteProvisionedConcurrencyConfigInput{
+		FunctionName: aws.String(name),
+		Qualifier:    aws.String(alias),
+	}
+	debugRequest(input)
+	result, err := l.svc.DeleteProvisionedConcurrencyConfig(input)
+	if err != nil {
+		l.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	return nil
+}
+
+// PutEventInvokeConfig puts asynchronous invocation configuration for the function or alias.
+// Qualifier is empty for unqualified function.
+func (l *LambdaRequest) PutEventInvokeConfig(name, qualifier string, async *entity.FunctionAsync) error {
+	l.log.Printf("Putting async invoke configuration for %s...\n", name)
+	input := &lambda.PutFunctionEventInvokeConfigInput{
+		FunctionName:             aws.String(name),
+		MaximumRetryAttempts:     async.MaximumRetryAttempts,
+		MaximumEventAgeInSeconds: async.MaximumEventAge,
+	}
+	if qualifier != "" {
+		input = input.SetQualifier(qualifier)
+	}
+	if async.OnSuccess != "" || async.OnFailure != "" {
+		dest := &lambda.DestinationConfig{}
+		if async.OnSuccess != "" {
+			dest.OnSuccess = &lambda.OnSuccess{Destination: aws.String(async.OnSuccess)}
+		}
+		if async.OnFailure != "" {
+			dest.OnFailure = &lambda.OnFailure{Destination: aws.String(async.OnFailure)}
+		}
+		input = input.SetDestinationConfig(dest)
+	}
+	debugRequest(input)
+	result, err := l.svc.PutFunctionEventInvokeConfig(input)
+	if err != nil {
+		l.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	return nil
+}
+
+// DeleteEventInvokeConfig deletes asynchronous invocation configuration if exists.
+func (l *LambdaRequest) DeleteEventInvokeConfig(name, qualifier string) error {
+	input := &lambda.DeleteFunctionEventInvokeConfigInput{
+		FunctionName: aws.String(name),
+	}
+	if qualifier != "" {
+		input = input.SetQualifier(qualifier)
+	}
+	debugRequest(input)
+	result, err := l.svc.DeleteFunctionEventInvokeConfig(input)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == lambda.ErrCodeResourceNotFoundException {
+			return nil
+		}
+		l.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	return nil
+}
+
+// InvokeFunctionAsync invokes function asynchronously.
+// Function reference can be qualified by alias like "name:alias".
+func (l *LambdaRequest) InvokeFunctionAsync(ref string, payload []byte) error {
+	name, qualifier := entity.SplitQualifier(ref)
+	input := &lambda.InvokeInput{
+		FunctionName:   aws.String(name),
+		InvocationType: aws.String(lambda.InvocationTypeEvent),
+		Payload:        payload,
+	}
+	if qualifier != "" {
+		input = input.SetQualifier(qualifier)
+	}
+	debugRequest(input)
+	result, err := l.svc.Invoke(input)
+	if err != nil {
+		l.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	return nil
+}
+
+// VersionExists checks published version existence.
+func (l *LambdaRequest) VersionExists(name, version string) bool {
+	input := &lambda.GetFunctionConfigurationInput{
+		FunctionName: aws.String(name),
+		Qualifier:    aws.String(version),
+	}
+	debugRequest(input)
+	if _, err := l.svc.GetFunctionConfiguration(input); err != nil {
+		return false
+	}
+	return true
+}
+
+// WaitFunctionUpdated waits until last update of the function has been completed.
+func (l *LambdaRequest) WaitFunctionUpdated(name string) error {
+	input := &lambda.GetFunctionConfigurationInput{
+		FunctionName: aws.String(name),
+	}
+	debugRequest(input)
+	if err := l.svc.WaitUntilFunctionUpdated(input); err != nil {
+		l.errorLog(err)
+		return err
+	}
+	return nil
+}
+
 func (l *LambdaRequest) InvokeFunction(name string, payload []byte) error {
 	input := &lambda.InvokeInput{
 		FunctionName: aws.String(name),
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 
@@ -19,6 +20,20 @@ func generateStatementId(sType string) string {
 	return fmt.Sprintf("ginger-statement-%s-%d", sType, time.Now().UnixNano())
 }
 
+// IsNotFound returns true if error means requested AWS resource doesn't exist.
+// Other errors like access denied or throttling don't tell existence.
+func IsNotFound(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+	switch aerr.Code() {
+	case "ResourceNotFoundException", "NotFoundException", "NotFound", "NoSuchBucket":
+		return true
+	}
+	return false
+}
+
 // Create common AWS session.
 func createAWSSession(c *config.Config) *session.Session {
 	conf := aws.NewConfig().WithRegion(c.Region)
@@ -94,3 +94,59 @@ func (s *S3Request) PutObject(bucket string, so *entity.StorageObject) error {
 	debugRequest(result)
 	return nil
 }
+
+// PutFunctionArtifact uploads function package as private object and returns version id.
+// Version id is empty if bucket versioning isn't enabled.
+func (s *S3Request) PutFunctionArtifact(bucket, key string, zipBytes []byte) (string, error) {
+	input := &s3.PutObjectInput{
+		Body:          aws.ReadSeekCloser(bytes.NewReader(zipBytes)),
+		Bucket:        aws.String(bucket),
+		Key:           aws.String(key),
+		ContentLength: aws.Int64(int64(len(zipBytes))),
+		ContentType:   aws.String("application/zip"),
+	}
+	debugRequest(input)
+	result, err := s.svc.PutObject(input)
+	if err != nil {
+		s.errorLog(err)
+		return "", err
+	}
+	debugRequest(result)
+	return aws.StringValue(result.VersionId), nil
+}
+
+// BucketExists checks bucket existence via HeadBucket.
+// Returns error if existence couldn't be determined, e.g. access denied.
+func (s *S3Request) BucketExists(bucket string) (bool, error) {
+	input := &s3.HeadBucketInput{
+		Bucket: aws.String(bucket),
+	}
+	debugRequest(input)
+	if _, err := s.svc.HeadBucket(input); err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		s.errorLog(err)
+		return false, err
+	}
+	return true, nil
+}
+
+// ListObjects lists all objects in the bucket.
+func (s *S3Request) ListObjects(bucket string) ([]*s3.Object, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+	}
+	debugRequest(input)
+	objects := []*s3.Object{}
+	err := s.svc.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		debugRequest(page)
+		objects = append(objects, page.Contents...)
+		return !lastPage
+	})
+	if err != nil {
+		s.errorLog(err)
+		return nil, err
+	}
+	return objects, nil
+}
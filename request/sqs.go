@@ -0,0 +1,105 @@
+package request
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/sqs"
+
+	"github.com/ysugimoto/ginger/config"
+	"github.com/ysugimoto/ginger/logger"
+)
+
+// SQSRequest is the struct which manages AWS SQS service.
+type SQSRequest struct {
+	svc    *sqs.SQS
+	log    *logger.Logger
+	config *config.Config
+}
+
+func NewSQS(c *config.Config) *SQSRequest {
+	return &SQSRequest{
+		config: c,
+		svc:    sqs.New(createAWSSession(c)),
+		log:    logger.WithNamespace("ginger.request.sqs"),
+	}
+}
+
+func (s *SQSRequest) errorLog(err error) {
+	if aerr, ok := err.(awserr.Error); ok {
+		switch aerr.Code() {
+		case sqs.ErrCodeQueueDoesNotExist:
+			s.log.Error(sqs.ErrCodeQueueDoesNotExist, aerr.Error())
+		case sqs.ErrCodeOverLimit:
+			s.log.Error(sqs.ErrCodeOverLimit, aerr.Error())
+		case sqs.ErrCodeReceiptHandleIsInvalid:
+			s.log.Error(sqs.ErrCodeReceiptHandleIsInvalid, aerr.Error())
+		default:
+			s.log.Error(aerr.Error())
+		}
+	} else {
+		s.log.Error(err.Error())
+	}
+}
+
+// GetQueueUrl resolves queue url from queue ARN.
+func (s *SQSRequest) GetQueueUrl(arn string) (string, error) {
+	// arn:aws:sqs:{region}:{account}:{queue name}
+	parts := strings.Split(arn, ":")
+	if len(parts) != 6 {
+		return "", fmt.Errorf("Invalid SQS queue ARN: %s", arn)
+	}
+	input := &sqs.GetQueueUrlInput{
+		QueueName:              aws.String(parts[5]),
+		QueueOwnerAWSAccountId: aws.String(parts[4]),
+	}
+	debugRequest(input)
+	result, err := s.svc.GetQueueUrl(input)
+	if err != nil {
+		s.errorLog(err)
+		return "", err
+	}
+	debugRequest(result)
+	return aws.StringValue(result.QueueUrl), nil
+}
+
+// ReceiveMessages receives messages from queue with long polling.
+// Received messages are invisible from other consumers while visibility timeout seconds,
+// so zero timeout can be used to peek messages.
+// Short polling may return empty result even if queue has messages, so wait up to waitTime seconds for them.
+func (s *SQSRequest) ReceiveMessages(queueUrl string, max, visibilityTimeout, waitTime int64) ([]*sqs.Message, error) {
+	input := &sqs.ReceiveMessageInput{
+		QueueUrl:              aws.String(queueUrl),
+		MaxNumberOfMessages:   aws.Int64(max),
+		VisibilityTimeout:     aws.Int64(visibilityTimeout),
+		WaitTimeSeconds:       aws.Int64(waitTime),
+		AttributeNames:        []*string{aws.String(sqs.QueueAttributeNameAll)},
+		MessageAttributeNames: []*string{aws.String(sqs.QueueAttributeNameAll)},
+	}
+	debugRequest(input)
+	result, err := s.svc.ReceiveMessage(input)
+	if err != nil {
+		s.errorLog(err)
+		return nil, err
+	}
+	debugRequest(result)
+	return result.Messages, nil
+}
+
+// DeleteMessage deletes received message from queue.
+func (s *SQSRequest) DeleteMessage(queueUrl string, message *sqs.Message) error {
+	input := &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(queueUrl),
+		ReceiptHandle: message.ReceiptHandle,
+	}
+	debugRequest(input)
+	result, err := s.svc.DeleteMessage(input)
+	if err != nil {
+		s.errorLog(err)
+		return err
+	}
+	debugRequest(result)
+	return nil
+}